@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"jtt/internal/config"
+	"jtt/internal/control"
+	"jtt/internal/export"
+	"jtt/internal/history"
+	"jtt/internal/logger"
+	"jtt/internal/state"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const cliUsage = `Usage: jtt [command]
+
+Without a command, jtt starts the menu bar app.
+
+Commands:
+  daemon   Run headless, controlled through the commands below
+  start    Start recording
+  stop     Stop recording and print the transcript
+  toggle   Start recording, or stop and print the transcript
+  status   Print the current state
+  cancel   Discard the current recording, or abort all queued transcriptions
+  export   Export history: jtt export [-format md|json|csv|srt|vtt] [-id ID]... <file|->
+  transcribe
+           Transcribe audio files into history and print the text:
+           jtt transcribe [-model M] [-language L] [-no-clean] [-copy] <file>...
+`
+
+// runCLI handles command-line subcommands. It reports false if args don't
+// name a subcommand, in which case the menu bar app should start.
+func runCLI(args []string) (int, bool) {
+	switch args[0] {
+	case "daemon":
+		return runDaemon(), true
+	case "start", "stop", "toggle", "status", "cancel":
+		return runClient(control.Request{Command: args[0], Args: args[1:]}), true
+	case "export":
+		return runExport(args[1:]), true
+	case "transcribe":
+		return runTranscribe(args[1:]), true
+	case "help", "-h", "--help":
+		fmt.Print(cliUsage)
+		return 0, true
+	}
+	return 0, false
+}
+
+// runClient sends a single request to the running instance and prints the
+// result: the transcript for stop/toggle, the state for status.
+func runClient(req control.Request) int {
+	resp, err := control.Send(control.SocketPath(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		if errors.Is(err, control.ErrNotRunning) {
+			return 2
+		}
+		return 1
+	}
+	if !resp.OK {
+		fmt.Fprintf(os.Stderr, "jtt: %s\n", resp.Error)
+		return 1
+	}
+
+	switch req.Command {
+	case "status":
+		fmt.Println(resp.State)
+	case "stop", "toggle":
+		if resp.Text != "" {
+			fmt.Println(resp.Text)
+		}
+	}
+	return 0
+}
+
+// runExport writes history entries to a file or stdout. It reads the history
+// file directly, so it works whether or not JTT is running.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "markdown, json, csv, srt or vtt (default: from file extension)")
+	var ids stringList
+	fs.Var(&ids, "id", "export only this entry (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: jtt export [-format md|json|csv|srt|vtt] [-id ID]... <file|->")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	f, err := parseExportFormat(*format, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		return 2
+	}
+
+	store, err := history.Open(history.DefaultPath(), 0, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		return 1
+	}
+	entries := store.Select(ids)
+
+	if path == "-" {
+		err = export.Write(os.Stdout, f, entries)
+	} else {
+		err = export.WriteFile(path, f, entries)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseExportFormat uses format if given, else the extension of path.
+func parseExportFormat(format, path string) (export.Format, error) {
+	if format != "" {
+		return export.ParseFormat(format)
+	}
+	if path == "-" {
+		return export.Markdown, nil
+	}
+	return export.FormatFromPath(path)
+}
+
+const transcribeUsage = "usage: jtt transcribe [-model M] [-language L] [-no-clean] [-copy] <file>..."
+
+// parseTranscribeArgs parses the transcribe command's flags and file paths.
+// The control socket uses it too, so the CLI can hand files to the running
+// app as-is.
+func parseTranscribeArgs(args []string) ([]string, TranscribeFileOptions, error) {
+	var opts TranscribeFileOptions
+	fs := flag.NewFlagSet("transcribe", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.WhisperModel, "model", "", "whisper model path or file name")
+	fs.StringVar(&opts.Language, "language", "", "spoken language")
+	fs.BoolVar(&opts.NoClean, "no-clean", false, "skip LLM cleaning")
+	fs.BoolVar(&opts.Copy, "copy", false, "copy the text to the clipboard")
+	if err := fs.Parse(args); err != nil {
+		return nil, opts, err
+	}
+	if fs.NArg() == 0 {
+		return nil, opts, errors.New(transcribeUsage)
+	}
+	return fs.Args(), opts, nil
+}
+
+// runTranscribe transcribes each file and prints its text. Files go through
+// the running app when there is one, so only one process writes history;
+// otherwise they are transcribed here.
+func runTranscribe(args []string) int {
+	paths, opts, err := parseTranscribeArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		return 2
+	}
+
+	var local *JTTApp
+	status := 0
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if len(paths) > 1 {
+			fmt.Printf("==> %s <==\n", path)
+		}
+
+		req := control.Request{Command: "transcribe", Args: append(opts.flags(), path)}
+		resp, err := control.Send(control.SocketPath(), req)
+		switch {
+		case errors.Is(err, control.ErrNotRunning):
+			if local == nil {
+				local = newJTTApp(loadConfig())
+			}
+			var entry config.TranscriptionEntry
+			entry, err = local.TranscribeFile(path, opts, printProgress)
+			fmt.Fprint(os.Stderr, "\r\033[K")
+			resp = &control.Response{OK: err == nil, Text: entry.LLMOutput}
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+			return 1
+		}
+
+		if !resp.OK {
+			fmt.Fprintf(os.Stderr, "jtt: %s: %s\n", filepath.Base(path), resp.Error)
+			status = 1
+			continue
+		}
+		fmt.Println(resp.Text)
+	}
+	return status
+}
+
+// printProgress shows a transcription's progress on one terminal line.
+func printProgress(p TranscribeProgress) {
+	if p.Percent > 0 {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s %d%%", p.Stage, p.Percent)
+	} else {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s...", p.Stage)
+	}
+}
+
+// flags turns opts back into transcribe command flags.
+func (o TranscribeFileOptions) flags() []string {
+	var args []string
+	if o.WhisperModel != "" {
+		args = append(args, "-model", o.WhisperModel)
+	}
+	if o.Language != "" {
+		args = append(args, "-language", o.Language)
+	}
+	if o.NoClean {
+		args = append(args, "-no-clean")
+	}
+	if o.Copy {
+		args = append(args, "-copy")
+	}
+	return args
+}
+
+// stringList is a flag.Value collecting repeated flags
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runDaemon runs JTT without the tray or settings window, serving the control
+// socket until interrupted.
+func runDaemon() int {
+	if err := logger.Init(); err != nil {
+		log.Printf("Failed to init logger: %v", err)
+	}
+	defer logger.Close()
+
+	jtt := newJTTApp(loadConfig())
+	ready := make(chan struct{})
+	srv, err := jtt.claimControl(ready)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jtt: %v\n", err)
+		return 1
+	}
+	defer srv.Close()
+
+	jtt.startAudio()
+	defer jtt.recorder.Close()
+	close(ready)
+
+	logger.Info("Daemon listening on %s", control.SocketPath())
+	fmt.Fprintf(os.Stderr, "jtt daemon listening on %s\n", control.SocketPath())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+
+	logger.Info("Daemon shutting down")
+	jtt.CancelRecording()
+	return 0
+}
+
+// claimControl binds the control socket, failing with
+// control.ErrAlreadyRunning if another instance has it. That instance owns
+// the recorder's files, so this must come before startAudio. Requests wait
+// until ready is closed.
+func (j *JTTApp) claimControl(ready <-chan struct{}) (*control.Server, error) {
+	return control.Listen(control.SocketPath(), func(req control.Request) control.Response {
+		<-ready
+		return j.handleControl(req)
+	})
+}
+
+// handleControl executes a control socket request against the app, with the
+// same semantics as the tray menu and hotkey.
+func (j *JTTApp) handleControl(req control.Request) control.Response {
+	var text string
+	var err error
+
+	switch req.Command {
+	case "start":
+		err = j.StartRecording()
+	case "stop":
+		text, err = j.StopRecording()
+	case "toggle":
+		if j.machine.Current() == state.Recording {
+			text, err = j.StopRecording()
+		} else {
+			err = j.StartRecording()
+		}
+	case "status":
+	case "cancel":
+		err = j.CancelRecording()
+	case "transcribe":
+		var paths []string
+		var opts TranscribeFileOptions
+		paths, opts, err = parseTranscribeArgs(req.Args)
+		if err == nil && len(paths) != 1 {
+			err = errors.New("transcribe takes one file per request")
+		}
+		if err == nil {
+			var entry config.TranscriptionEntry
+			entry, err = j.TranscribeFile(paths[0], opts, nil)
+			text = entry.LLMOutput
+		}
+	default:
+		err = fmt.Errorf("unknown command: %s", req.Command)
+	}
+
+	resp := control.Response{OK: err == nil, State: string(j.machine.Current()), Text: text}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
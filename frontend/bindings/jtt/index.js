@@ -9,5 +9,7 @@ export {
 
 export {
     DependencyStatus,
+    ReprocessOptions,
+    TranscribeFileOptions,
     WhisperModel
 } from "./models.js";
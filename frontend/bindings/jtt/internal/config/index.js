@@ -4,6 +4,11 @@
 
 export {
     Config,
+    FilteredText,
+    HallucinationPattern,
     HotkeyConfig,
+    Profile,
+    Revision,
+    Segment,
     TranscriptionEntry
 } from "./models.js";
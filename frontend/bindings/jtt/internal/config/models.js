@@ -19,6 +19,53 @@ export class Config {
              */
             this["whisperModel"] = "";
         }
+        if (!("transcriptionBackend" in $$source)) {
+            /**
+             * "whisper-cli" (default), "whisper-server" or "openai"
+             * @member
+             * @type {string}
+             */
+            this["transcriptionBackend"] = "";
+        }
+        if (!("transcriptionUrl" in $$source)) {
+            /**
+             * server address; empty uses the backend's default
+             * @member
+             * @type {string}
+             */
+            this["transcriptionUrl"] = "";
+        }
+        if (!("transcriptionModel" in $$source)) {
+            /**
+             * model name for OpenAI-compatible servers
+             * @member
+             * @type {string}
+             */
+            this["transcriptionModel"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["transcriptionApiKey"] = undefined;
+        }
+        if (!("language" in $$source)) {
+            /**
+             * whisper language code, or "auto" to detect; empty means English
+             * @member
+             * @type {string}
+             */
+            this["language"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * names and terms whisper should expect, passed as its initial prompt
+             * @member
+             * @type {string[] | undefined}
+             */
+            this["vocabulary"] = undefined;
+        }
         if (!("useOllama" in $$source)) {
             /**
              * @member
@@ -40,6 +87,13 @@ export class Config {
              */
             this["hotkey"] = (new HotkeyConfig());
         }
+        if (!("cancelHotkey" in $$source)) {
+            /**
+             * @member
+             * @type {HotkeyConfig}
+             */
+            this["cancelHotkey"] = (new HotkeyConfig());
+        }
         if (!("llmPrompt" in $$source)) {
             /**
              * @member
@@ -54,6 +108,22 @@ export class Config {
              */
             this["filterHallucinations"] = false;
         }
+        if (/** @type {any} */(false)) {
+            /**
+             * on top of the built-in ones
+             * @member
+             * @type {HallucinationPattern[] | undefined}
+             */
+            this["hallucinationPatterns"] = undefined;
+        }
+        if (!("noSpeechThreshold" in $$source)) {
+            /**
+             * drop segments whisper rates more likely than this to be silence; 0 uses the default, 1 disables
+             * @member
+             * @type {number}
+             */
+            this["noSpeechThreshold"] = 0;
+        }
         if (!("pauseMediaOnRecord" in $$source)) {
             /**
              * @member
@@ -68,6 +138,104 @@ export class Config {
              */
             this["microphone"] = "";
         }
+        if (!("audioBackend" in $$source)) {
+            /**
+             * "auto", "native", "pipewire", "pulse", "alsa" or "sox"
+             * @member
+             * @type {string}
+             */
+            this["audioBackend"] = "";
+        }
+        if (!("historyMaxEntries" in $$source)) {
+            /**
+             * 0 keeps all entries
+             * @member
+             * @type {number}
+             */
+            this["historyMaxEntries"] = 0;
+        }
+        if (!("historyMaxAgeDays" in $$source)) {
+            /**
+             * 0 keeps entries forever
+             * @member
+             * @type {number}
+             */
+            this["historyMaxAgeDays"] = 0;
+        }
+        if (!("autoStopOnSilence" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["autoStopOnSilence"] = false;
+        }
+        if (!("silenceThresholdDb" in $$source)) {
+            /**
+             * dBFS; 0 uses DefaultSilenceThresholdDB
+             * @member
+             * @type {number}
+             */
+            this["silenceThresholdDb"] = 0;
+        }
+        if (!("silenceStopSeconds" in $$source)) {
+            /**
+             * 0 uses DefaultSilenceStopSeconds
+             * @member
+             * @type {number}
+             */
+            this["silenceStopSeconds"] = 0;
+        }
+        if (!("trimSilence" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["trimSilence"] = false;
+        }
+        if (!("highPassFilter" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["highPassFilter"] = false;
+        }
+        if (!("noiseReduction" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["noiseReduction"] = false;
+        }
+        if (!("normalizeLoudness" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["normalizeLoudness"] = false;
+        }
+        if (!("preRollMs" in $$source)) {
+            /**
+             * audio kept from before recording starts; 0 closes the mic between recordings
+             * @member
+             * @type {number}
+             */
+            this["preRollMs"] = 0;
+        }
+        if (!("streamTranscription" in $$source)) {
+            /**
+             * transcribe chunks cut at pauses while still recording
+             * @member
+             * @type {boolean}
+             */
+            this["streamTranscription"] = false;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {Profile[] | undefined}
+             */
+            this["profiles"] = undefined;
+        }
 
         Object.assign(this, $$source);
     }
@@ -78,15 +246,124 @@ export class Config {
      * @returns {Config}
      */
     static createFrom($$source = {}) {
-        const $$createField3_0 = $$createType0;
+        const $$createField6_0 = $$createType0;
+        const $$createField9_0 = $$createType1;
+        const $$createField10_0 = $$createType1;
+        const $$createField13_0 = $$createType3;
+        const $$createField29_0 = $$createType5;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("vocabulary" in $$parsedSource) {
+            $$parsedSource["vocabulary"] = $$createField6_0($$parsedSource["vocabulary"]);
+        }
         if ("hotkey" in $$parsedSource) {
-            $$parsedSource["hotkey"] = $$createField3_0($$parsedSource["hotkey"]);
+            $$parsedSource["hotkey"] = $$createField9_0($$parsedSource["hotkey"]);
+        }
+        if ("cancelHotkey" in $$parsedSource) {
+            $$parsedSource["cancelHotkey"] = $$createField10_0($$parsedSource["cancelHotkey"]);
+        }
+        if ("hallucinationPatterns" in $$parsedSource) {
+            $$parsedSource["hallucinationPatterns"] = $$createField13_0($$parsedSource["hallucinationPatterns"]);
+        }
+        if ("profiles" in $$parsedSource) {
+            $$parsedSource["profiles"] = $$createField29_0($$parsedSource["profiles"]);
         }
         return new Config(/** @type {Partial<Config>} */($$parsedSource));
     }
 }
 
+/**
+ * FilteredText is a piece of whisper output the hallucination filter
+ * removed, and the rule that removed it.
+ */
+export class FilteredText {
+    /**
+     * Creates a new FilteredText instance.
+     * @param {Partial<FilteredText>} [$$source = {}] - The source object to create the FilteredText.
+     */
+    constructor($$source = {}) {
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("reason" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["reason"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {number | undefined}
+             */
+            this["start"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {number | undefined}
+             */
+            this["end"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new FilteredText instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {FilteredText}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new FilteredText(/** @type {Partial<FilteredText>} */($$parsedSource));
+    }
+}
+
+/**
+ * HallucinationPattern is a user rule for the hallucination filter. Plain
+ * patterns drop segments that say exactly that, ignoring case and
+ * punctuation; regex patterns remove every match.
+ */
+export class HallucinationPattern {
+    /**
+     * Creates a new HallucinationPattern instance.
+     * @param {Partial<HallucinationPattern>} [$$source = {}] - The source object to create the HallucinationPattern.
+     */
+    constructor($$source = {}) {
+        if (!("pattern" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["pattern"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {boolean | undefined}
+             */
+            this["regex"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HallucinationPattern instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HallucinationPattern}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new HallucinationPattern(/** @type {Partial<HallucinationPattern>} */($$parsedSource));
+    }
+}
+
 export class HotkeyConfig {
     /**
      * Creates a new HotkeyConfig instance.
@@ -117,8 +394,8 @@ export class HotkeyConfig {
      * @returns {HotkeyConfig}
      */
     static createFrom($$source = {}) {
-        const $$createField0_0 = $$createType1;
-        const $$createField1_0 = $$createType1;
+        const $$createField0_0 = $$createType0;
+        const $$createField1_0 = $$createType0;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
         if ("modifiers" in $$parsedSource) {
             $$parsedSource["modifiers"] = $$createField0_0($$parsedSource["modifiers"]);
@@ -130,12 +407,264 @@ export class HotkeyConfig {
     }
 }
 
+/**
+ * Profile is an extra dictation hotkey with its own settings. Empty fields
+ * fall back to the main settings.
+ */
+export class Profile {
+    /**
+     * Creates a new Profile instance.
+     * @param {Partial<Profile>} [$$source = {}] - The source object to create the Profile.
+     */
+    constructor($$source = {}) {
+        if (!("name" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["name"] = "";
+        }
+        if (!("hotkey" in $$source)) {
+            /**
+             * @member
+             * @type {HotkeyConfig}
+             */
+            this["hotkey"] = (new HotkeyConfig());
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["language"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * used ahead of the main vocabulary
+             * @member
+             * @type {string[] | undefined}
+             */
+            this["vocabulary"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Profile instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Profile}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType1;
+        const $$createField3_0 = $$createType0;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("hotkey" in $$parsedSource) {
+            $$parsedSource["hotkey"] = $$createField1_0($$parsedSource["hotkey"]);
+        }
+        if ("vocabulary" in $$parsedSource) {
+            $$parsedSource["vocabulary"] = $$createField3_0($$parsedSource["vocabulary"]);
+        }
+        return new Profile(/** @type {Partial<Profile>} */($$parsedSource));
+    }
+}
+
+/**
+ * Revision is the result of re-running an entry's audio or text through the
+ * pipeline with different settings. The entry's own fields keep the original.
+ */
+export class Revision {
+    /**
+     * Creates a new Revision instance.
+     * @param {Partial<Revision>} [$$source = {}] - The source object to create the Revision.
+     */
+    constructor($$source = {}) {
+        if (!("timestamp" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["timestamp"] = 0;
+        }
+        if (!("whisperModel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["whisperModel"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["language"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["llmPrompt"] = undefined;
+        }
+        if (!("whisperTime" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["whisperTime"] = 0;
+        }
+        if (!("whisperOutput" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["whisperOutput"] = "";
+        }
+        if (!("llmTime" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["llmTime"] = 0;
+        }
+        if (!("llmOutput" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["llmOutput"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["ollamaModel"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {Segment[] | undefined}
+             */
+            this["segments"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string[] | undefined}
+             */
+            this["vocabulary"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {FilteredText[] | undefined}
+             */
+            this["filtered"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Revision instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Revision}
+     */
+    static createFrom($$source = {}) {
+        const $$createField9_0 = $$createType7;
+        const $$createField10_0 = $$createType0;
+        const $$createField11_0 = $$createType9;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("segments" in $$parsedSource) {
+            $$parsedSource["segments"] = $$createField9_0($$parsedSource["segments"]);
+        }
+        if ("vocabulary" in $$parsedSource) {
+            $$parsedSource["vocabulary"] = $$createField10_0($$parsedSource["vocabulary"]);
+        }
+        if ("filtered" in $$parsedSource) {
+            $$parsedSource["filtered"] = $$createField11_0($$parsedSource["filtered"]);
+        }
+        return new Revision(/** @type {Partial<Revision>} */($$parsedSource));
+    }
+}
+
+/**
+ * Segment is a timed piece of an entry's whisper output. Times are seconds
+ * into the entry's saved audio.
+ */
+export class Segment {
+    /**
+     * Creates a new Segment instance.
+     * @param {Partial<Segment>} [$$source = {}] - The source object to create the Segment.
+     */
+    constructor($$source = {}) {
+        if (!("start" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["start"] = 0;
+        }
+        if (!("end" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["end"] = 0;
+        }
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("probability" in $$source)) {
+            /**
+             * average token probability, 0 to 1
+             * @member
+             * @type {number}
+             */
+            this["probability"] = 0;
+        }
+        if (!("noSpeechProb" in $$source)) {
+            /**
+             * whisper's estimate that there was no speech
+             * @member
+             * @type {number}
+             */
+            this["noSpeechProb"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Segment instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Segment}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Segment(/** @type {Partial<Segment>} */($$parsedSource));
+    }
+}
+
 export class TranscriptionEntry {
     /**
      * Creates a new TranscriptionEntry instance.
      * @param {Partial<TranscriptionEntry>} [$$source = {}] - The source object to create the TranscriptionEntry.
      */
     constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
         if (!("timestamp" in $$source)) {
             /**
              * @member
@@ -171,6 +700,105 @@ export class TranscriptionEntry {
              */
             this["llmOutput"] = "";
         }
+        if (!("whisperModel" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["whisperModel"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * language whisper transcribed in, detected for "auto"
+             * @member
+             * @type {string | undefined}
+             */
+            this["language"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * empty when cleaning was off
+             * @member
+             * @type {string | undefined}
+             */
+            this["ollamaModel"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * empty for the default hotkey
+             * @member
+             * @type {string | undefined}
+             */
+            this["profile"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["audioPath"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * file the audio was imported from; empty for dictations
+             * @member
+             * @type {string | undefined}
+             */
+            this["source"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {number | undefined}
+             */
+            this["audioDuration"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * length of the recording as captured, before any preprocessing
+             * @member
+             * @type {number | undefined}
+             */
+            this["recordedDuration"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {Segment[] | undefined}
+             */
+            this["segments"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * terms whisper was prompted with
+             * @member
+             * @type {string[] | undefined}
+             */
+            this["vocabulary"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * what the hallucination filter removed
+             * @member
+             * @type {FilteredText[] | undefined}
+             */
+            this["filtered"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {Revision[] | undefined}
+             */
+            this["revisions"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * why the dictation failed; the audio is kept so it can be re-run
+             * @member
+             * @type {string | undefined}
+             */
+            this["error"] = undefined;
+        }
 
         Object.assign(this, $$source);
     }
@@ -181,11 +809,37 @@ export class TranscriptionEntry {
      * @returns {TranscriptionEntry}
      */
     static createFrom($$source = {}) {
+        const $$createField14_0 = $$createType7;
+        const $$createField15_0 = $$createType0;
+        const $$createField16_0 = $$createType9;
+        const $$createField17_0 = $$createType11;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("segments" in $$parsedSource) {
+            $$parsedSource["segments"] = $$createField14_0($$parsedSource["segments"]);
+        }
+        if ("vocabulary" in $$parsedSource) {
+            $$parsedSource["vocabulary"] = $$createField15_0($$parsedSource["vocabulary"]);
+        }
+        if ("filtered" in $$parsedSource) {
+            $$parsedSource["filtered"] = $$createField16_0($$parsedSource["filtered"]);
+        }
+        if ("revisions" in $$parsedSource) {
+            $$parsedSource["revisions"] = $$createField17_0($$parsedSource["revisions"]);
+        }
         return new TranscriptionEntry(/** @type {Partial<TranscriptionEntry>} */($$parsedSource));
     }
 }
 
 // Private type creation functions
-const $$createType0 = HotkeyConfig.createFrom;
-const $$createType1 = $Create.Array($Create.Any);
+const $$createType0 = $Create.Array($Create.Any);
+const $$createType1 = HotkeyConfig.createFrom;
+const $$createType2 = HallucinationPattern.createFrom;
+const $$createType3 = $Create.Array($$createType2);
+const $$createType4 = Profile.createFrom;
+const $$createType5 = $Create.Array($$createType4);
+const $$createType6 = Segment.createFrom;
+const $$createType7 = $Create.Array($$createType6);
+const $$createType8 = FilteredText.createFrom;
+const $$createType9 = $Create.Array($$createType8);
+const $$createType10 = Revision.createFrom;
+const $$createType11 = $Create.Array($$createType10);
@@ -0,0 +1,264 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as config$0 from "../config/models.js";
+
+/**
+ * Fragment is a piece of a snippet; Match marks text that matched the query.
+ */
+export class Fragment {
+    /**
+     * Creates a new Fragment instance.
+     * @param {Partial<Fragment>} [$$source = {}] - The source object to create the Fragment.
+     */
+    constructor($$source = {}) {
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("match" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["match"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Fragment instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Fragment}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Fragment(/** @type {Partial<Fragment>} */($$parsedSource));
+    }
+}
+
+/**
+ * Page is one page of entries, newest first, plus the total entry count.
+ */
+export class Page {
+    /**
+     * Creates a new Page instance.
+     * @param {Partial<Page>} [$$source = {}] - The source object to create the Page.
+     */
+    constructor($$source = {}) {
+        if (!("entries" in $$source)) {
+            /**
+             * @member
+             * @type {config$0.TranscriptionEntry[]}
+             */
+            this["entries"] = [];
+        }
+        if (!("total" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["total"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Page instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Page}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType1;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("entries" in $$parsedSource) {
+            $$parsedSource["entries"] = $$createField0_0($$parsedSource["entries"]);
+        }
+        return new Page(/** @type {Partial<Page>} */($$parsedSource));
+    }
+}
+
+/**
+ * Query filters and ranks history entries. Zero-valued fields don't filter.
+ */
+export class Query {
+    /**
+     * Creates a new Query instance.
+     * @param {Partial<Query>} [$$source = {}] - The source object to create the Query.
+     */
+    constructor($$source = {}) {
+        if (!("text" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["text"] = "";
+        }
+        if (!("from" in $$source)) {
+            /**
+             * unix seconds, inclusive
+             * @member
+             * @type {number}
+             */
+            this["from"] = 0;
+        }
+        if (!("to" in $$source)) {
+            /**
+             * unix seconds, inclusive
+             * @member
+             * @type {number}
+             */
+            this["to"] = 0;
+        }
+        if (!("profile" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["profile"] = "";
+        }
+        if (!("model" in $$source)) {
+            /**
+             * matches the whisper or Ollama model
+             * @member
+             * @type {string}
+             */
+            this["model"] = "";
+        }
+        if (!("limit" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["limit"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Query instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Query}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Query(/** @type {Partial<Query>} */($$parsedSource));
+    }
+}
+
+/**
+ * SearchResult is a matching entry with its relevance score and snippets.
+ */
+export class SearchResult {
+    /**
+     * Creates a new SearchResult instance.
+     * @param {Partial<SearchResult>} [$$source = {}] - The source object to create the SearchResult.
+     */
+    constructor($$source = {}) {
+        if (!("entry" in $$source)) {
+            /**
+             * @member
+             * @type {config$0.TranscriptionEntry}
+             */
+            this["entry"] = (new config$0.TranscriptionEntry());
+        }
+        if (!("score" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["score"] = 0;
+        }
+        if (!("snippets" in $$source)) {
+            /**
+             * @member
+             * @type {Snippet[]}
+             */
+            this["snippets"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SearchResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SearchResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType0;
+        const $$createField2_0 = $$createType3;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("entry" in $$parsedSource) {
+            $$parsedSource["entry"] = $$createField0_0($$parsedSource["entry"]);
+        }
+        if ("snippets" in $$parsedSource) {
+            $$parsedSource["snippets"] = $$createField2_0($$parsedSource["snippets"]);
+        }
+        return new SearchResult(/** @type {Partial<SearchResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * Snippet is an excerpt of one entry field around the query matches.
+ */
+export class Snippet {
+    /**
+     * Creates a new Snippet instance.
+     * @param {Partial<Snippet>} [$$source = {}] - The source object to create the Snippet.
+     */
+    constructor($$source = {}) {
+        if (!("field" in $$source)) {
+            /**
+             * "whisperOutput" or "llmOutput"
+             * @member
+             * @type {string}
+             */
+            this["field"] = "";
+        }
+        if (!("fragments" in $$source)) {
+            /**
+             * @member
+             * @type {Fragment[]}
+             */
+            this["fragments"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Snippet instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Snippet}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType5;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("fragments" in $$parsedSource) {
+            $$parsedSource["fragments"] = $$createField1_0($$parsedSource["fragments"]);
+        }
+        return new Snippet(/** @type {Partial<Snippet>} */($$parsedSource));
+    }
+}
+
+// Private type creation functions
+const $$createType0 = config$0.TranscriptionEntry.createFrom;
+const $$createType1 = $Create.Array($$createType0);
+const $$createType2 = Snippet.createFrom;
+const $$createType3 = $Create.Array($$createType2);
+const $$createType4 = Fragment.createFrom;
+const $$createType5 = $Create.Array($$createType4);
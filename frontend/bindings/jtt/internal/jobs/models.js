@@ -0,0 +1,83 @@
+// @ts-check
+// Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
+// This file is automatically generated. DO NOT EDIT
+
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import { Create as $Create } from "@wailsio/runtime";
+
+/**
+ * Job is one recording waiting for or going through the pipeline. Its audio
+ * file belongs to the job: RunFunc moves it somewhere permanent, even if the
+ * job fails, and the queue deletes it if the job is cancelled.
+ */
+export class Job {
+    /**
+     * Creates a new Job instance.
+     * @param {Partial<Job>} [$$source = {}] - The source object to create the Job.
+     */
+    constructor($$source = {}) {
+        if (!("id" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["id"] = "";
+        }
+        if (!("created" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["created"] = 0;
+        }
+        if (!("audioPath" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["audioPath"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * profile the recording was made with
+             * @member
+             * @type {string | undefined}
+             */
+            this["profile"] = undefined;
+        }
+        if (!("stage" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["stage"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["text"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * @member
+             * @type {string | undefined}
+             */
+            this["error"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Job instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Job}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Job(/** @type {Partial<Job>} */($$parsedSource));
+    }
+}
@@ -3,5 +3,7 @@
 // This file is automatically generated. DO NOT EDIT
 
 export {
-    Microphone
+    Microphone,
+    Recovered,
+    Recovery
 } from "./models.js";
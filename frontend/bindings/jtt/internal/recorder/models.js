@@ -6,6 +6,11 @@
 // @ts-ignore: Unused imports
 import { Create as $Create } from "@wailsio/runtime";
 
+/**
+ * Microphone is an input device. IDs are "<backend>:<device>", e.g.
+ * "pulse:alsa_input.usb-Blue_Yeti-00.analog-stereo", so they stay stable
+ * across renames and reboots; the empty ID is the system default.
+ */
 export class Microphone {
     /**
      * Creates a new Microphone instance.
@@ -40,3 +45,97 @@ export class Microphone {
         return new Microphone(/** @type {Partial<Microphone>} */($$parsedSource));
     }
 }
+
+/**
+ * Recovered is a recording a crashed run never transcribed.
+ */
+export class Recovered {
+    /**
+     * Creates a new Recovered instance.
+     * @param {Partial<Recovered>} [$$source = {}] - The source object to create the Recovered.
+     */
+    constructor($$source = {}) {
+        if (!("path" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["path"] = "";
+        }
+        if (!("duration" in $$source)) {
+            /**
+             * @member
+             * @type {number}
+             */
+            this["duration"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Recovered instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Recovered}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new Recovered(/** @type {Partial<Recovered>} */($$parsedSource));
+    }
+}
+
+/**
+ * Recovery describes what a crashed run left behind.
+ */
+export class Recovery {
+    /**
+     * Creates a new Recovery instance.
+     * @param {Partial<Recovery>} [$$source = {}] - The source object to create the Recovery.
+     */
+    constructor($$source = {}) {
+        if (/** @type {any} */(false)) {
+            /**
+             * orphaned recorder process that was stopped
+             * @member
+             * @type {number | undefined}
+             */
+            this["killedPid"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * recordings that can still be transcribed, oldest first
+             * @member
+             * @type {Recovered[] | undefined}
+             */
+            this["recordings"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * of all the recordings
+             * @member
+             * @type {number | undefined}
+             */
+            this["duration"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Recovery instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Recovery}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType1;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("recordings" in $$parsedSource) {
+            $$parsedSource["recordings"] = $$createField1_0($$parsedSource["recordings"]);
+        }
+        return new Recovery(/** @type {Partial<Recovery>} */($$parsedSource));
+    }
+}
+
+// Private type creation functions
+const $$createType0 = Recovered.createFrom;
+const $$createType1 = $Create.Array($$createType0);
@@ -16,12 +16,33 @@ import { Call as $Call, CancellablePromise as $CancellablePromise, Create as $Cr
 import * as config$0 from "./internal/config/models.js";
 // eslint-disable-next-line @typescript-eslint/ban-ts-comment
 // @ts-ignore: Unused imports
+import * as history$0 from "./internal/history/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
+import * as jobs$0 from "./internal/jobs/models.js";
+// eslint-disable-next-line @typescript-eslint/ban-ts-comment
+// @ts-ignore: Unused imports
 import * as recorder$0 from "./internal/recorder/models.js";
 
 // eslint-disable-next-line @typescript-eslint/ban-ts-comment
 // @ts-ignore: Unused imports
 import * as $models from "./models.js";
 
+/**
+ * @param {string} id
+ * @returns {$CancellablePromise<void>}
+ */
+export function CancelJob(id) {
+    return $Call.ByID(67199400, id);
+}
+
+/**
+ * @returns {$CancellablePromise<void>}
+ */
+export function CancelRecording() {
+    return $Call.ByID(2940125224);
+}
+
 /**
  * @returns {$CancellablePromise<$models.DependencyStatus>}
  */
@@ -31,6 +52,28 @@ export function CheckDependencies() {
     }));
 }
 
+/**
+ * @returns {$CancellablePromise<void>}
+ */
+export function ClearHistory() {
+    return $Call.ByID(473494944);
+}
+
+/**
+ * @param {string} id
+ * @returns {$CancellablePromise<void>}
+ */
+export function DeleteHistoryEntry(id) {
+    return $Call.ByID(308489112, id);
+}
+
+/**
+ * @returns {$CancellablePromise<void>}
+ */
+export function DiscardRecovered() {
+    return $Call.ByID(2160657040);
+}
+
 /**
  * @param {string} name
  * @param {string} url
@@ -40,12 +83,56 @@ export function DownloadWhisperModel(name, url) {
     return $Call.ByID(1739716008, name, url);
 }
 
+/**
+ * ExportHistory writes the entries with the given IDs, or all entries if ids
+ * is empty, to path. format is markdown, json, csv, srt or vtt; if empty it
+ * is inferred from the file extension.
+ * @param {string[]} ids
+ * @param {string} format
+ * @param {string} path
+ * @returns {$CancellablePromise<void>}
+ */
+export function ExportHistory(ids, format, path) {
+    return $Call.ByID(3623733743, ids, format, path);
+}
+
+/**
+ * FitVocabulary returns how many of terms fit in whisper's prompt, in order,
+ * so the settings window can show what gets left out.
+ * @param {string[]} terms
+ * @returns {$CancellablePromise<number>}
+ */
+export function FitVocabulary(terms) {
+    return $Call.ByID(451455806, terms);
+}
+
+/**
+ * GetActiveMicrophone returns the device used by the current or last recording
+ * @returns {$CancellablePromise<recorder$0.Microphone>}
+ */
+export function GetActiveMicrophone() {
+    return $Call.ByID(2670249243).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType1($result);
+    }));
+}
+
+/**
+ * GetAudioBackends returns "auto" plus the capture backends usable on this
+ * machine.
+ * @returns {$CancellablePromise<string[]>}
+ */
+export function GetAudioBackends() {
+    return $Call.ByID(3148058468).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType2($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<$models.WhisperModel[]>}
  */
 export function GetAvailableWhisperModels() {
     return $Call.ByID(728014776).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType2($result);
+        return $$createType4($result);
     }));
 }
 
@@ -54,7 +141,7 @@ export function GetAvailableWhisperModels() {
  */
 export function GetConfig() {
     return $Call.ByID(724951059).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType4($result);
+        return $$createType6($result);
     }));
 }
 
@@ -70,19 +157,34 @@ export function GetDefaultPrompt() {
  */
 export function GetDownloadedModels() {
     return $Call.ByID(1274740742).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType5($result);
+        return $$createType2($result);
     }));
 }
 
 /**
- * @returns {$CancellablePromise<config$0.TranscriptionEntry[]>}
+ * GetHistory returns a page of history entries, newest first. A limit of zero
+ * returns everything after offset.
+ * @param {number} offset
+ * @param {number} limit
+ * @returns {$CancellablePromise<history$0.Page>}
  */
-export function GetHistory() {
-    return $Call.ByID(2594147347).then(/** @type {($result: any) => any} */(($result) => {
+export function GetHistory(offset, limit) {
+    return $Call.ByID(2594147347, offset, limit).then(/** @type {($result: any) => any} */(($result) => {
         return $$createType7($result);
     }));
 }
 
+/**
+ * GetJobs returns queued, running and recently finished dictations, oldest
+ * first
+ * @returns {$CancellablePromise<jobs$0.Job[]>}
+ */
+export function GetJobs() {
+    return $Call.ByID(1446128849).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType9($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<string>}
  */
@@ -95,7 +197,7 @@ export function GetLogPath() {
  */
 export function GetMicrophones() {
     return $Call.ByID(3309817626).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType9($result);
+        return $$createType10($result);
     }));
 }
 
@@ -104,7 +206,7 @@ export function GetMicrophones() {
  */
 export function GetOllamaModels() {
     return $Call.ByID(3782478637).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType5($result);
+        return $$createType2($result);
     }));
 }
 
@@ -115,6 +217,16 @@ export function GetRecentLogs() {
     return $Call.ByID(1711462601);
 }
 
+/**
+ * GetRecoveredRecording returns the recordings interrupted by a crash, or nil
+ * @returns {$CancellablePromise<recorder$0.Recovery | null>}
+ */
+export function GetRecoveredRecording() {
+    return $Call.ByID(3276185261).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType12($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<string>}
  */
@@ -137,6 +249,19 @@ export function IsOllamaRunning() {
     return $Call.ByID(2205589612);
 }
 
+/**
+ * Reprocess re-runs an entry's saved audio or text with different settings
+ * and returns the entry with the new revision appended.
+ * @param {string} entryID
+ * @param {$models.ReprocessOptions} options
+ * @returns {$CancellablePromise<config$0.TranscriptionEntry>}
+ */
+export function Reprocess(entryID, options) {
+    return $Call.ByID(2140218291, entryID, options).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType13($result);
+    }));
+}
+
 /**
  * @param {config$0.Config | null} cfg
  * @returns {$CancellablePromise<void>}
@@ -145,6 +270,17 @@ export function SaveConfig(cfg) {
     return $Call.ByID(2215473916, cfg);
 }
 
+/**
+ * SearchHistory runs a ranked full-text search over whisper and LLM output.
+ * @param {history$0.Query} query
+ * @returns {$CancellablePromise<history$0.SearchResult[]>}
+ */
+export function SearchHistory(query) {
+    return $Call.ByID(1828614489, query).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType15($result);
+    }));
+}
+
 /**
  * @returns {$CancellablePromise<void>}
  */
@@ -159,6 +295,26 @@ export function StopRecording() {
     return $Call.ByID(3172466554);
 }
 
+/**
+ * TranscribeFile transcribes an existing audio file into history without
+ * pasting it
+ * @param {string} path
+ * @param {$models.TranscribeFileOptions} opts
+ * @returns {$CancellablePromise<config$0.TranscriptionEntry>}
+ */
+export function TranscribeFile(path, opts) {
+    return $Call.ByID(510483318, path, opts).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType13($result);
+    }));
+}
+
+/**
+ * @returns {$CancellablePromise<string>}
+ */
+export function TranscribeRecovered() {
+    return $Call.ByID(3408380597);
+}
+
 /**
  * @param {string[]} modifiers
  * @param {string} key
@@ -170,12 +326,18 @@ export function UpdateHotkey(modifiers, key) {
 
 // Private type creation functions
 const $$createType0 = $models.DependencyStatus.createFrom;
-const $$createType1 = $models.WhisperModel.createFrom;
-const $$createType2 = $Create.Array($$createType1);
-const $$createType3 = config$0.Config.createFrom;
-const $$createType4 = $Create.Nullable($$createType3);
-const $$createType5 = $Create.Array($Create.Any);
-const $$createType6 = config$0.TranscriptionEntry.createFrom;
-const $$createType7 = $Create.Array($$createType6);
-const $$createType8 = recorder$0.Microphone.createFrom;
+const $$createType1 = recorder$0.Microphone.createFrom;
+const $$createType2 = $Create.Array($Create.Any);
+const $$createType3 = $models.WhisperModel.createFrom;
+const $$createType4 = $Create.Array($$createType3);
+const $$createType5 = config$0.Config.createFrom;
+const $$createType6 = $Create.Nullable($$createType5);
+const $$createType7 = history$0.Page.createFrom;
+const $$createType8 = jobs$0.Job.createFrom;
 const $$createType9 = $Create.Array($$createType8);
+const $$createType10 = $Create.Array($$createType1);
+const $$createType11 = recorder$0.Recovery.createFrom;
+const $$createType12 = $Create.Nullable($$createType11);
+const $$createType13 = config$0.TranscriptionEntry.createFrom;
+const $$createType14 = history$0.SearchResult.createFrom;
+const $$createType15 = $Create.Array($$createType14);
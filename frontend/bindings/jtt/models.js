@@ -62,6 +62,123 @@ export class DependencyStatus {
     }
 }
 
+/**
+ * ReprocessOptions selects what to re-run for a history entry. Empty fields
+ * fall back to the current settings.
+ */
+export class ReprocessOptions {
+    /**
+     * Creates a new ReprocessOptions instance.
+     * @param {Partial<ReprocessOptions>} [$$source = {}] - The source object to create the ReprocessOptions.
+     */
+    constructor($$source = {}) {
+        if (!("transcribe" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["transcribe"] = false;
+        }
+        if (!("clean" in $$source)) {
+            /**
+             * @member
+             * @type {boolean}
+             */
+            this["clean"] = false;
+        }
+        if (!("whisperModel" in $$source)) {
+            /**
+             * model path, or file name in the model directory
+             * @member
+             * @type {string}
+             */
+            this["whisperModel"] = "";
+        }
+        if (!("language" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["language"] = "";
+        }
+        if (!("llmPrompt" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["llmPrompt"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ReprocessOptions instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ReprocessOptions}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new ReprocessOptions(/** @type {Partial<ReprocessOptions>} */($$parsedSource));
+    }
+}
+
+/**
+ * TranscribeFileOptions tunes a file transcription. Empty fields fall back
+ * to the current settings.
+ */
+export class TranscribeFileOptions {
+    /**
+     * Creates a new TranscribeFileOptions instance.
+     * @param {Partial<TranscribeFileOptions>} [$$source = {}] - The source object to create the TranscribeFileOptions.
+     */
+    constructor($$source = {}) {
+        if (!("whisperModel" in $$source)) {
+            /**
+             * model path, or file name in the model directory
+             * @member
+             * @type {string}
+             */
+            this["whisperModel"] = "";
+        }
+        if (!("language" in $$source)) {
+            /**
+             * @member
+             * @type {string}
+             */
+            this["language"] = "";
+        }
+        if (!("noClean" in $$source)) {
+            /**
+             * skip LLM cleaning even if it is enabled
+             * @member
+             * @type {boolean}
+             */
+            this["noClean"] = false;
+        }
+        if (!("copy" in $$source)) {
+            /**
+             * also copy the text to the clipboard
+             * @member
+             * @type {boolean}
+             */
+            this["copy"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new TranscribeFileOptions instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {TranscribeFileOptions}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new TranscribeFileOptions(/** @type {Partial<TranscribeFileOptions>} */($$parsedSource));
+    }
+}
+
 export class WhisperModel {
     /**
      * Creates a new WhisperModel instance.
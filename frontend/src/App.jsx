@@ -3,6 +3,141 @@ import { Events } from '@wailsio/runtime';
 import * as JTTService from '../bindings/jtt/jttservice.js';
 import './App.css';
 
+const HISTORY_PAGE_SIZE = 50;
+
+// Segments whisper was less sure of than this are underlined in history
+const LOW_CONFIDENCE = 0.6;
+
+const LANGUAGES = [
+  ['en', 'English'], ['de', 'German'], ['es', 'Spanish'], ['fr', 'French'],
+  ['it', 'Italian'], ['pt', 'Portuguese'], ['nl', 'Dutch'], ['pl', 'Polish'],
+  ['sv', 'Swedish'], ['tr', 'Turkish'], ['ru', 'Russian'], ['uk', 'Ukrainian'],
+  ['zh', 'Chinese'], ['ja', 'Japanese'], ['ko', 'Korean'], ['hi', 'Hindi'],
+];
+
+// LanguageSelect picks a whisper language. With inherit, an empty value
+// means the main setting.
+function LanguageSelect({ value, inherit, onChange }) {
+  return (
+    <select value={value} onChange={(e) => onChange(e.target.value)}>
+      {inherit && <option value="">Same as main setting</option>}
+      <option value="auto">Auto-detect</option>
+      {LANGUAGES.map(([code, name]) => (
+        <option key={code} value={code}>{name}</option>
+      ))}
+    </select>
+  );
+}
+
+// VocabularyEditor edits a list of terms, one per line, saving when it loses
+// focus. It warns when some terms don't fit in whisper's prompt.
+function VocabularyEditor({ terms, onChange }) {
+  const [text, setText] = useState((terms || []).join('\n'));
+  const [fit, setFit] = useState(null);
+
+  useEffect(() => {
+    setText((terms || []).join('\n'));
+    JTTService.FitVocabulary(terms || []).then(setFit);
+  }, [terms]);
+
+  const count = (terms || []).length;
+  return (
+    <>
+      <textarea
+        className="prompt-editor"
+        rows={4}
+        placeholder={'One per line, e.g.\nKubernetes\nJTTService'}
+        value={text}
+        onChange={(e) => setText(e.target.value)}
+        onBlur={() => onChange(text.split('\n').map((t) => t.trim()).filter(Boolean))}
+      />
+      {fit !== null && fit < count && (
+        <p className="hint hint-warning">
+          Only the first {fit} of {count} terms fit in whisper's prompt.
+        </p>
+      )}
+    </>
+  );
+}
+
+// PatternEditor edits hallucination patterns, one per line, with /.../ marking
+// a regular expression. It saves when it loses focus and shows why a save was
+// rejected.
+function PatternEditor({ patterns, onChange }) {
+  const format = (ps) => (ps || []).map((p) => (p.regex ? `/${p.pattern}/` : p.pattern)).join('\n');
+  const [text, setText] = useState(format(patterns));
+  const [error, setError] = useState(null);
+
+  useEffect(() => {
+    setText(format(patterns));
+  }, [patterns]);
+
+  const save = () => {
+    const parsed = text.split('\n').map((t) => t.trim()).filter(Boolean).map((t) =>
+      t.length > 2 && t.startsWith('/') && t.endsWith('/')
+        ? { pattern: t.slice(1, -1), regex: true }
+        : { pattern: t }
+    );
+    onChange(parsed).then(() => setError(null)).catch((err) => setError(String(err)));
+  };
+
+  return (
+    <>
+      <textarea
+        className="prompt-editor"
+        rows={3}
+        placeholder={'One per line, e.g.\nSee you next time.\n/(?i)^\\s*bye\\.?$/'}
+        value={text}
+        onChange={(e) => setText(e.target.value)}
+        onBlur={save}
+      />
+      {error && <p className="hint hint-warning">{error}</p>}
+    </>
+  );
+}
+
+// ShortcutPicker edits a hotkey as modifier toggles plus one key. An optional
+// shortcut can be turned off by picking no key.
+function ShortcutPicker({ hotkey, onChange, optional }) {
+  return (
+    <div className="shortcut-config">
+      <div className="modifier-buttons">
+        {['cmd', 'ctrl', 'alt', 'shift'].map((mod) => (
+          <button
+            key={mod}
+            className={`modifier-btn ${hotkey?.modifiers?.includes(mod) ? 'active' : ''}`}
+            onClick={() => {
+              const current = hotkey?.modifiers || [];
+              const newMods = current.includes(mod)
+                ? current.filter(m => m !== mod)
+                : [...current, mod];
+              onChange({ ...hotkey, modifiers: newMods });
+            }}
+          >
+            {mod === 'cmd' ? '⌘' : mod === 'ctrl' ? '⌃' : mod === 'alt' ? '⌥' : '⇧'}
+          </button>
+        ))}
+      </div>
+      <span className="shortcut-plus">+</span>
+      <select
+        className="key-select"
+        value={hotkey?.keys?.[0] || (optional ? '' : 'r')}
+        onChange={(e) => onChange({ ...hotkey, keys: e.target.value ? [e.target.value] : [] })}
+      >
+        {optional && <option value="">None</option>}
+        {[...'abcdefghijklmnopqrstuvwxyz'].map((k) => (
+          <option key={k} value={k}>{k.toUpperCase()}</option>
+        ))}
+        {['0','1','2','3','4','5','6','7','8','9'].map((k) => (
+          <option key={k} value={k}>{k}</option>
+        ))}
+        <option value="space">Space</option>
+        <option value="escape">Esc</option>
+      </select>
+    </div>
+  );
+}
+
 function App() {
   const [config, setConfig] = useState(null);
   const [state, setState] = useState('idle');
@@ -16,17 +151,43 @@ function App() {
   const [modelsExpanded, setModelsExpanded] = useState(false);
   const [activeTab, setActiveTab] = useState('settings');
   const [history, setHistory] = useState([]);
+  const [historyTotal, setHistoryTotal] = useState(0);
+  const [searchText, setSearchText] = useState('');
+  const [searchResults, setSearchResults] = useState([]);
   const [defaultPrompt, setDefaultPrompt] = useState('');
   const [microphones, setMicrophones] = useState([]);
+  const [audioBackends, setAudioBackends] = useState([]);
+  const [activeDevice, setActiveDevice] = useState(null);
+  const [level, setLevel] = useState(null);
+  const [inputWarning, setInputWarning] = useState('');
+  const [dictationError, setDictationError] = useState('');
+  const [partial, setPartial] = useState('');
+  const [recovered, setRecovered] = useState(null);
+  const [jobs, setJobs] = useState([]);
+  const [recovering, setRecovering] = useState(false);
 
   useEffect(() => {
     loadData();
-    Events.On('state-change', (newState) => setState(newState));
+    Events.On('state-change', (newState) => {
+      setState(newState);
+      if (newState === 'recording') {
+        setLevel(null);
+        setInputWarning('');
+        setPartial('');
+        setDictationError('');
+      }
+    });
+    Events.On('input-level', (l) => setLevel(l));
+    Events.On('input-silent', (message) => setInputWarning(message));
+    Events.On('partial-transcript', (text) => setPartial(text));
+    Events.On('jobs-change', (list) => setJobs(list || []));
+    Events.On('dictation-error', (message) => setDictationError(message));
+    Events.On('device-change', (event) => setActiveDevice(event));
   }, []);
 
   const loadData = async () => {
     try {
-      const [cfg, appState, models, running, depStatus, whisper, downloaded, hist, defPrompt, mics] = await Promise.all([
+      const [cfg, appState, models, running, depStatus, whisper, downloaded, hist, defPrompt, mics, backends, rec, jobList] = await Promise.all([
         JTTService.GetConfig(),
         JTTService.GetState(),
         JTTService.GetOllamaModels(),
@@ -34,9 +195,12 @@ function App() {
         JTTService.CheckDependencies(),
         JTTService.GetAvailableWhisperModels(),
         JTTService.GetDownloadedModels(),
-        JTTService.GetHistory(),
+        JTTService.GetHistory(0, HISTORY_PAGE_SIZE),
         JTTService.GetDefaultPrompt(),
         JTTService.GetMicrophones(),
+        JTTService.GetAudioBackends(),
+        JTTService.GetRecoveredRecording(),
+        JTTService.GetJobs(),
       ]);
       setConfig(cfg);
       setState(appState);
@@ -45,9 +209,13 @@ function App() {
       setDeps(depStatus);
       setWhisperModels(whisper || []);
       setDownloadedModels(downloaded || []);
-      setHistory(hist || []);
+      setHistory(hist?.entries || []);
+      setHistoryTotal(hist?.total || 0);
       setDefaultPrompt(defPrompt || '');
       setMicrophones(mics || []);
+      setAudioBackends(backends || []);
+      setRecovered(rec);
+      setJobs(jobList || []);
     } catch (err) {
       console.error('Failed to load data:', err);
     }
@@ -60,6 +228,37 @@ function App() {
     await JTTService.SaveConfig(newConfig);
   };
 
+  const updateProfile = (index, updates) => {
+    const profiles = config.profiles.map((p, i) => (i === index ? { ...p, ...updates } : p));
+    saveConfig({ profiles });
+  };
+
+  const addProfile = () => {
+    const profiles = config.profiles || [];
+    saveConfig({
+      profiles: [...profiles, { name: `Profile ${profiles.length + 1}`, hotkey: { modifiers: ['cmd', 'shift'], keys: ['1'] } }],
+    });
+  };
+
+  const removeProfile = (index) => {
+    saveConfig({ profiles: config.profiles.filter((_, i) => i !== index) });
+  };
+
+  const handleRecovered = async (transcribe) => {
+    setRecovering(true);
+    try {
+      if (transcribe) {
+        await JTTService.TranscribeRecovered();
+      } else {
+        await JTTService.DiscardRecovered();
+      }
+    } catch (err) {
+      console.error('Failed to handle interrupted recording:', err);
+    }
+    setRecovering(false);
+    await loadData();
+  };
+
   const handleInstall = async (dep) => {
     setInstalling(dep);
     await JTTService.InstallDependency(dep);
@@ -74,12 +273,50 @@ function App() {
     setDownloading(null);
   };
 
+  const loadMoreHistory = async () => {
+    const page = await JTTService.GetHistory(history.length, HISTORY_PAGE_SIZE);
+    setHistory([...history, ...(page?.entries || [])]);
+    setHistoryTotal(page?.total || 0);
+  };
+
+  const searchHistory = async (text) => {
+    setSearchText(text);
+    if (!text.trim()) {
+      setSearchResults([]);
+      return;
+    }
+    const results = await JTTService.SearchHistory({ text, limit: HISTORY_PAGE_SIZE });
+    setSearchResults(results || []);
+  };
+
+  const reprocessEntry = async (id) => {
+    const updated = await JTTService.Reprocess(id, { transcribe: true, clean: config.useOllama });
+    setHistory(history.map((entry) => entry.id === id ? updated : entry));
+  };
+
+  const deleteHistoryEntry = async (id) => {
+    await JTTService.DeleteHistoryEntry(id);
+    setHistory(history.filter((entry) => entry.id !== id));
+    setHistoryTotal(historyTotal - 1);
+  };
+
+  const clearHistory = async () => {
+    await JTTService.ClearHistory();
+    setHistory([]);
+    setHistoryTotal(0);
+  };
+
   if (!config || !deps) {
     return <div className="loading">Loading...</div>;
   }
 
   const missingDeps = !deps.sox || !deps.whisper;
 
+  const formatElapsed = (seconds) => {
+    const s = Math.floor(seconds);
+    return `${Math.floor(s / 60)}:${String(s % 60).padStart(2, '0')}`;
+  };
+
   const formatTime = (timestamp) => {
     return new Date(timestamp * 1000).toLocaleString();
   };
@@ -90,11 +327,23 @@ function App() {
         <h1>JTT</h1>
         <div className={`status status-${state}`}>
           {state === 'idle' && 'Ready'}
-          {state === 'recording' && 'Recording'}
+          {state === 'recording' && `Recording ${formatElapsed(level?.elapsed || 0)}`}
           {state === 'processing' && 'Processing'}
         </div>
       </header>
 
+      {state === 'recording' && (
+        <div className="level-meter">
+          <div className="level-bar" style={{ width: `${Math.min(100, (level?.peak || 0) * 100)}%` }} />
+        </div>
+      )}
+      {state === 'recording' && inputWarning && (
+        <p className="hint hint-warning">{inputWarning}</p>
+      )}
+      {(state === 'recording' || state === 'processing') && partial && (
+        <p className="partial-transcript">{partial}</p>
+      )}
+
       <nav className="tabs">
         <button 
           className={`tab ${activeTab === 'settings' ? 'active' : ''}`}
@@ -118,6 +367,37 @@ function App() {
 
       {activeTab === 'settings' && (
         <>
+          {dictationError && (
+            <section className="section warning">
+              <h2>Dictation Failed</h2>
+              <p>{dictationError}</p>
+              <div className="test-buttons">
+                <button className="btn-secondary" onClick={() => setDictationError('')}>
+                  Dismiss
+                </button>
+              </div>
+            </section>
+          )}
+
+          {recovered && (
+            <section className="section warning">
+              <h2>Interrupted Recording{recovered.recordings.length > 1 ? 's' : ''}</h2>
+              <p>
+                {recovered.recordings.length > 1
+                  ? `${recovered.recordings.length} recordings (${Math.round(recovered.duration)}s) were not transcribed when JTT last quit unexpectedly.`
+                  : `A ${Math.round(recovered.duration)}s recording was not transcribed when JTT last quit unexpectedly.`}
+              </p>
+              <div className="test-buttons">
+                <button className="btn-primary" onClick={() => handleRecovered(true)} disabled={recovering}>
+                  {recovering ? 'Transcribing...' : 'Transcribe'}
+                </button>
+                <button className="btn-secondary" onClick={() => handleRecovered(false)} disabled={recovering}>
+                  Discard
+                </button>
+              </div>
+            </section>
+          )}
+
           {missingDeps && (
             <section className="section warning">
               <h2>Missing Dependencies</h2>
@@ -151,6 +431,20 @@ function App() {
 
           <section className="section">
             <h2>Microphone</h2>
+            <div className="form-group">
+              <label>Audio Backend</label>
+              <select
+                value={config.audioBackend || 'auto'}
+                onChange={async (e) => {
+                  await saveConfig({ audioBackend: e.target.value, microphone: '' });
+                  setMicrophones(await JTTService.GetMicrophones() || []);
+                }}
+              >
+                {audioBackends.map((b) => (
+                  <option key={b} value={b}>{b === 'auto' ? 'Automatic' : b}</option>
+                ))}
+              </select>
+            </div>
             <div className="form-group">
               <label>Input Device</label>
               <select
@@ -164,12 +458,79 @@ function App() {
               <p className="hint">
                 Select the microphone to use for recording.
               </p>
+              {activeDevice?.warning && (
+                <p className="hint hint-warning">{activeDevice.warning}</p>
+              )}
+            </div>
+            <div className="form-group">
+              <label>Pre-roll</label>
+              <select
+                value={config.preRollMs || 0}
+                onChange={(e) => saveConfig({ preRollMs: parseInt(e.target.value, 10) })}
+              >
+                <option value={0}>Off</option>
+                <option value={250}>250 ms</option>
+                <option value={500}>500 ms</option>
+                <option value={1000}>1 second</option>
+              </select>
+              <p className="hint">
+                Keeps the microphone open between recordings so the first word isn't cut off. The menu bar shows "mic open" while it is on.
+              </p>
             </div>
           </section>
 
           <section className="section">
             <h2>Whisper Model</h2>
-            {downloadedModels.length > 0 && (
+            <div className="form-group">
+              <label>Transcription Backend</label>
+              <select
+                value={config.transcriptionBackend || 'whisper-cli'}
+                onChange={(e) => saveConfig({ transcriptionBackend: e.target.value, transcriptionUrl: '', transcriptionModel: '' })}
+              >
+                <option value="whisper-cli">whisper-cli (local, loads the model each time)</option>
+                <option value="whisper-server">whisper-server (whisper.cpp HTTP server)</option>
+                <option value="openai">OpenAI-compatible server</option>
+              </select>
+            </div>
+            {config.transcriptionBackend && config.transcriptionBackend !== 'whisper-cli' && (
+              <>
+                <div className="form-group">
+                  <label>Server URL</label>
+                  <input
+                    type="text"
+                    placeholder={config.transcriptionBackend === 'openai' ? 'http://127.0.0.1:8000' : 'http://127.0.0.1:8080'}
+                    value={config.transcriptionUrl || ''}
+                    onChange={(e) => saveConfig({ transcriptionUrl: e.target.value })}
+                  />
+                </div>
+                {config.transcriptionBackend === 'openai' && (
+                  <>
+                    <div className="form-group">
+                      <label>Model</label>
+                      <input
+                        type="text"
+                        placeholder="whisper-1"
+                        value={config.transcriptionModel || ''}
+                        onChange={(e) => saveConfig({ transcriptionModel: e.target.value })}
+                      />
+                    </div>
+                    <div className="form-group">
+                      <label>API Key</label>
+                      <input
+                        type="password"
+                        placeholder="Only if the server needs one"
+                        value={config.transcriptionApiKey || ''}
+                        onChange={(e) => saveConfig({ transcriptionApiKey: e.target.value })}
+                      />
+                    </div>
+                  </>
+                )}
+                <p className="hint">
+                  The server keeps its model loaded between dictations, so transcription starts right away.
+                </p>
+              </>
+            )}
+            {(!config.transcriptionBackend || config.transcriptionBackend === 'whisper-cli') && downloadedModels.length > 0 && (
               <div className="form-group">
                 <label>Current Model</label>
                 <select
@@ -182,6 +543,26 @@ function App() {
                 </select>
               </div>
             )}
+            <div className="form-group">
+              <label>Language</label>
+              <LanguageSelect
+                value={config.language || 'en'}
+                onChange={(language) => saveConfig({ language })}
+              />
+              <p className="hint">
+                Other languages and auto-detection need a multilingual model (one without <code>.en</code>).
+              </p>
+            </div>
+            <div className="form-group">
+              <label>Vocabulary</label>
+              <VocabularyEditor
+                terms={config.vocabulary}
+                onChange={(vocabulary) => saveConfig({ vocabulary })}
+              />
+              <p className="hint">
+                Names, products and identifiers whisper should spell the way you do.
+              </p>
+            </div>
             <div className="form-group">
               <label className="toggle">
                 <input
@@ -192,9 +573,37 @@ function App() {
                 <span>Filter hallucinations</span>
               </label>
               <p className="hint">
-                Filter out common whisper hallucinations like "you" when recording silence.
+                Filter out what whisper makes up on silence and noise: "you", "Thanks for watching", [BLANK_AUDIO], looping phrases and segments it rates as unlikely speech.
               </p>
             </div>
+            {config.filterHallucinations !== false && (
+              <>
+                <div className="form-group">
+                  <label>Extra hallucination patterns</label>
+                  <PatternEditor
+                    patterns={config.hallucinationPatterns}
+                    onChange={(hallucinationPatterns) => saveConfig({ hallucinationPatterns })}
+                  />
+                  <p className="hint">
+                    Segments that say exactly this are dropped. Wrap a line in <code>/.../</code> to remove every match of a regular expression instead.
+                  </p>
+                </div>
+                <div className="form-group">
+                  <label>No-speech threshold</label>
+                  <input
+                    type="number"
+                    min="0.05"
+                    max="1"
+                    step="0.05"
+                    value={config.noSpeechThreshold || 0.6}
+                    onChange={(e) => saveConfig({ noSpeechThreshold: parseFloat(e.target.value) || 0 })}
+                  />
+                  <p className="hint">
+                    Drops segments whisper thinks are more likely than this to be silence. 1 keeps them all. Not every server reports this.
+                  </p>
+                </div>
+              </>
+            )}
             <div className="accordion">
               <button 
                 className="accordion-header"
@@ -290,44 +699,137 @@ function App() {
             <p className="hint">
               Hold this shortcut to record. Release to stop and transcribe.
             </p>
-            <div className="shortcut-config">
-              <div className="modifier-buttons">
-                {['cmd', 'ctrl', 'alt', 'shift'].map((mod) => (
-                  <button
-                    key={mod}
-                    className={`modifier-btn ${config.hotkey?.modifiers?.includes(mod) ? 'active' : ''}`}
-                    onClick={() => {
-                      const current = config.hotkey?.modifiers || [];
-                      const newMods = current.includes(mod)
-                        ? current.filter(m => m !== mod)
-                        : [...current, mod];
-                      saveConfig({ hotkey: { ...config.hotkey, modifiers: newMods } });
-                    }}
-                  >
-                    {mod === 'cmd' ? '⌘' : mod === 'ctrl' ? '⌃' : mod === 'alt' ? '⌥' : '⇧'}
-                  </button>
-                ))}
-              </div>
-              <span className="shortcut-plus">+</span>
-              <select
-                className="key-select"
-                value={config.hotkey?.keys?.[0] || 'r'}
-                onChange={(e) => saveConfig({ hotkey: { ...config.hotkey, keys: [e.target.value] } })}
-              >
-                {[...'abcdefghijklmnopqrstuvwxyz'].map((k) => (
-                  <option key={k} value={k}>{k.toUpperCase()}</option>
-                ))}
-                {['0','1','2','3','4','5','6','7','8','9'].map((k) => (
-                  <option key={k} value={k}>{k}</option>
-                ))}
-                <option value="space">Space</option>
-              </select>
-            </div>
+            <ShortcutPicker
+              hotkey={config.hotkey}
+              onChange={(hotkey) => saveConfig({ hotkey })}
+            />
+            <p className="hint" style={{marginTop: '12px'}}>
+              Press this shortcut to discard the recording, or abort queued transcriptions.
+            </p>
+            <ShortcutPicker
+              hotkey={config.cancelHotkey}
+              onChange={(cancelHotkey) => saveConfig({ cancelHotkey })}
+              optional
+            />
             <p className="hint" style={{marginTop: '12px'}}>
               Restart the app to apply shortcut changes.
             </p>
           </section>
 
+          <section className="section">
+            <h2>Profiles</h2>
+            <p className="hint">
+              Extra shortcuts that dictate with their own settings, e.g. one per language. Restart the app to apply shortcut changes.
+            </p>
+            {(config.profiles || []).map((profile, i) => (
+              <div key={i} className="form-group profile">
+                <div className="profile-header">
+                  <input
+                    type="text"
+                    placeholder="Name"
+                    value={profile.name}
+                    onChange={(e) => updateProfile(i, { name: e.target.value })}
+                  />
+                  <button className="link-btn history-delete" onClick={() => removeProfile(i)}>Remove</button>
+                </div>
+                <ShortcutPicker
+                  hotkey={profile.hotkey}
+                  onChange={(hotkey) => updateProfile(i, { hotkey })}
+                />
+                <label>Language</label>
+                <LanguageSelect
+                  value={profile.language || ''}
+                  inherit
+                  onChange={(language) => updateProfile(i, { language })}
+                />
+                <label>Vocabulary</label>
+                <VocabularyEditor
+                  terms={profile.vocabulary}
+                  onChange={(vocabulary) => updateProfile(i, { vocabulary })}
+                />
+              </div>
+            ))}
+            <button className="btn-secondary" onClick={addProfile}>Add Profile</button>
+          </section>
+
+          <section className="section">
+            <h2>Audio Processing</h2>
+            <div className="form-group">
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.autoStopOnSilence || false}
+                  onChange={(e) => saveConfig({ autoStopOnSilence: e.target.checked })}
+                />
+                <span>Stop recording when I stop talking</span>
+              </label>
+              {config.autoStopOnSilence && (
+                <input
+                  type="number"
+                  min="0.5"
+                  step="0.5"
+                  value={config.silenceStopSeconds || 2}
+                  onChange={(e) => saveConfig({ silenceStopSeconds: parseFloat(e.target.value) || 0 })}
+                />
+              )}
+              <p className="hint">
+                Ends the recording after this many seconds of silence following speech.
+              </p>
+            </div>
+            <div className="form-group">
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.streamTranscription || false}
+                  onChange={(e) => saveConfig({ streamTranscription: e.target.checked })}
+                />
+                <span>Transcribe while recording</span>
+              </label>
+              <p className="hint">
+                Transcribes long dictations in pieces as you pause, so the text is ready soon after you stop. Silence is not trimmed in this mode.
+              </p>
+            </div>
+            <div className="form-group">
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.trimSilence || false}
+                  onChange={(e) => saveConfig({ trimSilence: e.target.checked })}
+                />
+                <span>Trim silence before transcribing</span>
+              </label>
+            </div>
+            <div className="form-group">
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.highPassFilter || false}
+                  onChange={(e) => saveConfig({ highPassFilter: e.target.checked })}
+                />
+                <span>Remove low rumble</span>
+              </label>
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.noiseReduction || false}
+                  onChange={(e) => saveConfig({ noiseReduction: e.target.checked })}
+                />
+                <span>Reduce background noise</span>
+              </label>
+              <label className="toggle">
+                <input
+                  type="checkbox"
+                  checked={config.normalizeLoudness || false}
+                  onChange={(e) => saveConfig({ normalizeLoudness: e.target.checked })}
+                />
+                <span>Normalize loudness</span>
+              </label>
+              <p className="hint">
+                Cleans up the recording before Whisper hears it. Noise reduction helps with fans and hum but can hurt clean recordings.
+              </p>
+            </div>
+          </section>
+
           <section className="section">
             <h2>Media Control</h2>
             <div className="form-group">
@@ -353,17 +855,37 @@ function App() {
             <div className="test-buttons">
               <button
                 className="btn-primary"
-                onClick={() => state === 'idle' 
-                  ? JTTService.StartRecording() 
-                  : JTTService.StopRecording()
+                onClick={() => state === 'recording'
+                  ? JTTService.StopRecording()
+                  : JTTService.StartRecording()
                 }
-                disabled={state === 'processing'}
               >
-                {state === 'idle' && 'Start Recording'}
-                {state === 'recording' && 'Stop Recording'}
-                {state === 'processing' && 'Processing...'}
+                {state === 'recording' ? 'Stop Recording' : 'Start Recording'}
               </button>
+              {(state === 'recording' || state === 'processing') && (
+                <button
+                  className="btn-secondary"
+                  onClick={() => JTTService.CancelRecording()}
+                >
+                  {state === 'processing' ? 'Cancel All' : 'Cancel'}
+                </button>
+              )}
             </div>
+            {jobs.length > 0 && (
+              <ul className="job-list">
+                {jobs.map((job) => (
+                  <li key={job.id} className={`job job-${job.stage}`}>
+                    <span>{formatTime(job.created)}</span>
+                    <span className="job-stage">{job.error || job.stage}</span>
+                    {!['done', 'failed', 'cancelled'].includes(job.stage) && (
+                      <button className="link-btn" onClick={() => JTTService.CancelJob(job.id)}>
+                        Cancel
+                      </button>
+                    )}
+                  </li>
+                ))}
+              </ul>
+            )}
           </section>
         </>
       )}
@@ -371,20 +893,81 @@ function App() {
       {activeTab === 'history' && (
         <section className="section">
           <h2>Transcription History</h2>
-          {history.length === 0 ? (
+          <div className="form-group">
+            <input
+              type="text"
+              placeholder="Search transcriptions..."
+              value={searchText}
+              onChange={(e) => searchHistory(e.target.value)}
+            />
+          </div>
+          {searchText.trim() ? (
+            searchResults.length === 0 ? (
+              <p className="hint">No matching transcriptions.</p>
+            ) : (
+              <div className="history-list">
+                {searchResults.map((result) => (
+                  <div key={result.entry.id} className="history-entry">
+                    <div className="history-header">
+                      <span className="history-time">{formatTime(result.entry.timestamp)}</span>
+                    </div>
+                    {result.snippets.map((snippet) => (
+                      <div key={snippet.field} className="history-row">
+                        <div className="history-label">{snippet.field === 'llmOutput' ? 'LLM' : 'Whisper'}</div>
+                        <div className="history-output">
+                          {snippet.fragments.map((f, i) => f.match ? <mark key={i}>{f.text}</mark> : <span key={i}>{f.text}</span>)}
+                        </div>
+                      </div>
+                    ))}
+                  </div>
+                ))}
+              </div>
+            )
+          ) : history.length === 0 ? (
             <p className="hint">No transcriptions yet. Record something to see history.</p>
           ) : (
             <div className="history-list">
-              {[...history].reverse().map((entry, idx) => (
-                <div key={entry.timestamp} className="history-entry">
+              {history.map((entry) => (
+                <div key={entry.id || entry.timestamp} className="history-entry">
                   <div className="history-header">
-                    <span className="history-time">{formatTime(entry.timestamp)}</span>
+                    <span className="history-time">
+                      {formatTime(entry.timestamp)}
+                      {entry.profile && <span className="history-timing"> {entry.profile}</span>}
+                      {entry.language && <span className="history-timing"> [{entry.language}]</span>}
+                      {entry.audioDuration > 0 && (
+                        <span className="history-timing">
+                          {' '}{entry.recordedDuration > 0 && `${entry.recordedDuration.toFixed(1)}s → `}{entry.audioDuration.toFixed(1)}s audio
+                        </span>
+                      )}
+                    </span>
+                    <span>
+                      {entry.audioPath && (
+                        <button className="link-btn history-delete" onClick={() => reprocessEntry(entry.id)}>Re-run</button>
+                      )}
+                      <button className="link-btn history-delete" onClick={() => deleteHistoryEntry(entry.id)}>Delete</button>
+                    </span>
                   </div>
+                  {entry.error && (
+                    <div className="history-row">
+                      <div className="history-label">Failed</div>
+                      <div className="history-output">{entry.error}</div>
+                    </div>
+                  )}
                   <div className="history-row">
                     <div className="history-label">
                       Whisper <span className="history-timing">({entry.whisperTime.toFixed(2)}s)</span>
                     </div>
-                    <div className="history-output">{entry.whisperOutput}</div>
+                    <div className="history-output">
+                      {entry.segments?.length > 0 ? entry.segments.map((seg, i) => (
+                        <span
+                          key={i}
+                          className={seg.probability < LOW_CONFIDENCE ? 'low-confidence' : undefined}
+                          title={`${seg.start.toFixed(1)}s–${seg.end.toFixed(1)}s, ${Math.round(seg.probability * 100)}% confident`}
+                        >
+                          {seg.text}
+                        </span>
+                      )) : entry.whisperOutput}
+                    </div>
                   </div>
                   <div className="history-row">
                     <div className="history-label">
@@ -392,10 +975,42 @@ function App() {
                     </div>
                     <div className="history-output">{entry.llmOutput}</div>
                   </div>
+                  {entry.filtered?.length > 0 && (
+                    <details className="history-filtered">
+                      <summary className="history-timing">Filtered {entry.filtered.length} hallucination{entry.filtered.length > 1 ? 's' : ''}</summary>
+                      <ul>
+                        {entry.filtered.map((f, i) => (
+                          <li key={i}>
+                            <span className="filtered-text">{f.text}</span> <span className="history-timing">({f.reason})</span>
+                          </li>
+                        ))}
+                      </ul>
+                    </details>
+                  )}
+                  {entry.revisions?.length > 0 && (
+                    <div className="history-row">
+                      <div className="history-label">
+                        Latest re-run <span className="history-timing">({entry.revisions[entry.revisions.length - 1].whisperModel})</span>
+                      </div>
+                      <div className="history-output">{entry.revisions[entry.revisions.length - 1].llmOutput}</div>
+                    </div>
+                  )}
                 </div>
               ))}
             </div>
           )}
+          {!searchText.trim() && history.length > 0 && (
+            <div className="test-buttons history-actions">
+              {history.length < historyTotal && (
+                <button className="btn-secondary" onClick={loadMoreHistory}>
+                  Load More ({historyTotal - history.length} older)
+                </button>
+              )}
+              <button className="btn-secondary" onClick={clearHistory}>
+                Clear History
+              </button>
+            </div>
+          )}
         </section>
       )}
 
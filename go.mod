@@ -3,6 +3,7 @@ module jtt
 go 1.25
 
 require (
+	github.com/gen2brain/malgo v0.11.24
 	github.com/wailsapp/wails/v3 v3.0.0-alpha.65
 	golang.design/x/hotkey v0.4.1
 )
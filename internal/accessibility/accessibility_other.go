@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package accessibility
+
+// CheckAccessibility reports true: only macOS gates synthetic key presses
+// behind a permission.
+func CheckAccessibility(prompt bool) bool {
+	return true
+}
@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Convert writes src, in any format ffmpeg or sox can read, to dst in the
+// recording format whisper expects (16 kHz mono 16-bit WAV). Cancelling ctx
+// kills the converter.
+func Convert(ctx context.Context, src, dst string) error {
+	if info, err := ReadInfo(src); err == nil &&
+		info.SampleRate == SampleRate && info.Channels == Channels && info.BitsPerSample == BitsPerSample {
+		return copyFile(src, dst)
+	}
+
+	var cmd *exec.Cmd
+	if ffmpeg := findBinary("ffmpeg"); ffmpeg != "" {
+		cmd = exec.CommandContext(ctx, ffmpeg,
+			"-nostdin", "-y", "-loglevel", "error",
+			"-i", src,
+			"-vn", "-map_metadata", "-1", "-fflags", "+bitexact",
+			"-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", "-f", "wav",
+			dst,
+		)
+	} else if sox := findBinary("sox"); sox != "" {
+		cmd = exec.CommandContext(ctx, sox, src,
+			"-r", "16000", "-c", "1", "-b", "16", "-e", "signed-integer", "-t", "wav",
+			dst,
+		)
+	} else {
+		return errors.New("converting audio files needs ffmpeg or sox")
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to convert %s: %w: %s", filepath.Base(src), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// findBinary locates a command, checking common Homebrew paths since bundled
+// macOS apps don't inherit shell PATH. It returns "" if it isn't installed.
+func findBinary(name string) string {
+	for _, dir := range []string{"/opt/homebrew/bin", "/usr/local/bin"} {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	if p, err := exec.LookPath(name); err == nil {
+		return p
+	}
+	return ""
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+// ReadSamples returns the samples of a recording-format WAV file scaled to
+// -1..1.
+func ReadSamples(path string) ([]float64, error) {
+	info, err := ReadInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.SampleRate != SampleRate || info.Channels != Channels || info.BitsPerSample != BitsPerSample {
+		return nil, fmt.Errorf("%s: expected %d Hz mono %d-bit audio", path, SampleRate, BitsPerSample)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := make([]byte, info.DataSize)
+	if n, err := f.ReadAt(data, info.DataOffset); n < len(data) {
+		return nil, err
+	}
+
+	samples := make([]float64, len(data)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(data[2*i:]))) / 32768
+	}
+	return samples, nil
+}
+
+// WriteSamples replaces path with a WAV file holding samples, clipping them
+// to -1..1. It writes to a temporary file first so a failed write doesn't
+// lose the original.
+func WriteSamples(path string, samples []float64) error {
+	pcm := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		v := math.Round(s * 32768)
+		v = math.Max(-32768, math.Min(32767, v))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(v)))
+	}
+
+	tmp := path + ".tmp"
+	w, err := Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(pcm); err != nil {
+		w.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// FrameDuration is the analysis window for level and voice detection.
+const FrameDuration = 30 * time.Millisecond
+
+const frameSamples = SampleRate * int(FrameDuration/time.Millisecond) / 1000
+
+// speechFrames is how many consecutive loud frames count as speech, so a
+// single click or keyboard tap doesn't.
+const speechFrames = 3
+
+// Level returns the RMS and peak of little-endian 16-bit samples, both
+// scaled to 0..1.
+func Level(pcm []byte) (rms, peak float64) {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+		sum += s * s
+		if a := math.Abs(s); a > peak {
+			peak = a
+		}
+	}
+	return math.Sqrt(sum / float64(n)), peak
+}
+
+// DBFS converts a 0..1 level to decibels relative to full scale. Digital
+// silence is reported as -inf.
+func DBFS(level float64) float64 {
+	return 20 * math.Log10(level)
+}
+
+// VAD is an energy-based voice activity detector fed with raw PCM as it is
+// captured. It is not safe for concurrent use.
+type VAD struct {
+	threshold float64 // dBFS
+	frame     []byte
+	loud      int // consecutive loud frames
+	speech    bool
+	silent    int // frames since speech last ended
+}
+
+// NewVAD returns a detector treating frames quieter than thresholdDB as
+// silence.
+func NewVAD(thresholdDB float64) *VAD {
+	return &VAD{
+		threshold: thresholdDB,
+		frame:     make([]byte, 0, frameSamples*2),
+	}
+}
+
+// Write analyses p, which may split frames and samples anywhere.
+func (v *VAD) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := min(cap(v.frame)-len(v.frame), len(p))
+		v.frame = append(v.frame, p[:take]...)
+		p = p[take:]
+		if len(v.frame) == cap(v.frame) {
+			v.analyse(v.frame)
+			v.frame = v.frame[:0]
+		}
+	}
+	return n, nil
+}
+
+func (v *VAD) analyse(frame []byte) {
+	rms, _ := Level(frame)
+	if DBFS(rms) > v.threshold {
+		v.loud++
+		if v.loud >= speechFrames {
+			v.speech = true
+			v.silent = 0
+		}
+		return
+	}
+	v.loud = 0
+	if v.speech {
+		v.silent++
+	}
+}
+
+// HeardSpeech reports whether any speech has been detected so far.
+func (v *VAD) HeardSpeech() bool {
+	return v.speech
+}
+
+// Silence returns how long it has been quiet since speech last ended, or 0
+// if no speech has been heard yet.
+func (v *VAD) Silence() time.Duration {
+	return time.Duration(v.silent) * FrameDuration
+}
@@ -0,0 +1,112 @@
+// Package audio reads and writes the 16-bit PCM WAV files passed between the
+// recorder and the transcriber.
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Info describes a PCM WAV file.
+type Info struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	DataOffset    int64 // byte offset of the sample data
+	DataSize      int64 // bytes of sample data
+	Unfinished    bool  // the header sizes were never filled in
+}
+
+// Duration returns the length of the audio in seconds.
+func (i Info) Duration() float64 {
+	bytesPerSecond := i.SampleRate * i.Channels * i.BitsPerSample / 8
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return float64(i.DataSize) / float64(bytesPerSecond)
+}
+
+var errNotWAV = errors.New("not a WAV file")
+
+// ReadInfo parses the header of the WAV file at path. A data chunk whose size
+// was never filled in (the recorder was killed before finalizing the header)
+// is assumed to run to the end of the file.
+func ReadInfo(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil {
+		return Info{}, errNotWAV
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return Info{}, errNotWAV
+	}
+
+	var info Info
+	offset := int64(12)
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(f, hdr[:]); err != nil {
+			return Info{}, fmt.Errorf("%s: missing data chunk", path)
+		}
+		id := string(hdr[0:4])
+		size := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			var fmtChunk [16]byte
+			if size < 16 {
+				return Info{}, fmt.Errorf("%s: short fmt chunk", path)
+			}
+			if _, err := io.ReadFull(f, fmtChunk[:]); err != nil {
+				return Info{}, err
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(fmtChunk[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:8]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(fmtChunk[14:16]))
+		case "data":
+			info.DataOffset = offset
+			remaining := stat.Size() - offset
+			if size == 0 && remaining > 0 {
+				info.Unfinished = true
+			}
+			if size == 0 || size > remaining {
+				size = remaining
+			}
+			info.DataSize = size
+			if info.SampleRate == 0 {
+				return Info{}, fmt.Errorf("%s: data before fmt chunk", path)
+			}
+			return info, nil
+		}
+
+		// Chunks are padded to an even size
+		skip := size + size%2
+		if _, err := f.Seek(offset+skip, io.SeekStart); err != nil {
+			return Info{}, err
+		}
+		offset += skip
+	}
+}
+
+// Duration returns the length in seconds of the WAV file at path.
+func Duration(path string) (float64, error) {
+	info, err := ReadInfo(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Duration(), nil
+}
@@ -0,0 +1,105 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// Whisper expects 16 kHz mono 16-bit PCM, so every capture backend records
+// in this format.
+const (
+	SampleRate    = 16000
+	Channels      = 1
+	BitsPerSample = 16
+)
+
+const headerSize = 44
+
+// Writer streams PCM samples into a WAV file. The header is written with
+// zero sizes up front and patched on Close, so a file left behind by a crash
+// is still readable by ReadInfo.
+type Writer struct {
+	f    *os.File
+	size int64
+}
+
+// Create creates a WAV file at path in the standard recording format.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{f: f}
+	if _, err := f.Write(header(0)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends little-endian 16-bit PCM bytes.
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close fills in the header sizes and closes the file.
+func (w *Writer) Close() error {
+	if _, err := w.f.WriteAt(header(w.size), 0); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Finalize fills in the header sizes of a file left unfinished by a Writer
+// that was never closed, e.g. because the app crashed mid-recording.
+func Finalize(path string) error {
+	info, err := ReadInfo(path)
+	if err != nil {
+		return err
+	}
+	if !info.Unfinished {
+		return nil
+	}
+	if info.DataOffset != headerSize {
+		return fmt.Errorf("%s: unexpected header layout", path)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	// Drop a trailing half sample
+	size := info.DataSize &^ 1
+	if err := f.Truncate(headerSize + size); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.WriteAt(header(size), 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// header returns a 44-byte PCM WAV header for dataSize bytes of samples.
+func header(dataSize int64) []byte {
+	h := make([]byte, headerSize)
+	copy(h[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:8], uint32(36+dataSize))
+	copy(h[8:12], "WAVE")
+	copy(h[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:20], 16)
+	binary.LittleEndian.PutUint16(h[20:22], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:24], Channels)
+	binary.LittleEndian.PutUint32(h[24:28], SampleRate)
+	binary.LittleEndian.PutUint32(h[28:32], SampleRate*Channels*BitsPerSample/8)
+	binary.LittleEndian.PutUint16(h[32:34], Channels*BitsPerSample/8)
+	binary.LittleEndian.PutUint16(h[34:36], BitsPerSample)
+	copy(h[36:40], "data")
+	binary.LittleEndian.PutUint32(h[40:44], uint32(dataSize))
+	return h
+}
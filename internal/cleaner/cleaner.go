@@ -2,6 +2,7 @@ package cleaner
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,7 +28,8 @@ func New(model string, enabled bool, prompt string) *Cleaner {
 	return &Cleaner{model: model, enabled: enabled, prompt: prompt}
 }
 
-func (c *Cleaner) Clean(text string) (*CleanResult, error) {
+// Clean sends text to Ollama for cleanup. Cancelling ctx aborts the request.
+func (c *Cleaner) Clean(ctx context.Context, text string) (*CleanResult, error) {
 	if !c.enabled {
 		return &CleanResult{Text: text, Seconds: 0}, nil
 	}
@@ -45,9 +47,18 @@ func (c *Cleaner) Clean(text string) (*CleanResult, error) {
 		return &CleanResult{Text: text, Seconds: 0}, err
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ollamaURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return &CleanResult{Text: text, Seconds: 0}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	start := time.Now()
-	resp, err := http.Post(ollamaURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return &CleanResult{Text: text, Seconds: 0}, ctx.Err()
+		}
 		return &CleanResult{Text: text, Seconds: 0}, fmt.Errorf("ollama not running: %w", err)
 	}
 	defer resp.Body.Close()
@@ -71,6 +82,15 @@ func (c *Cleaner) Clean(text string) (*CleanResult, error) {
 	}, nil
 }
 
+// Process implements pipeline.Processor, returning only the cleaned text.
+func (c *Cleaner) Process(ctx context.Context, text string) (string, error) {
+	result, err := c.Clean(ctx, text)
+	if err != nil {
+		return text, err
+	}
+	return result.Text, nil
+}
+
 func ListModels() ([]string, error) {
 	resp, err := http.Get("http://localhost:11434/api/tags")
 	if err != nil {
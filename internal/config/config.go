@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type HotkeyConfig struct {
@@ -12,24 +13,120 @@ type HotkeyConfig struct {
 }
 
 type Config struct {
-	WhisperModel         string       `json:"whisperModel"`
-	UseOllama            bool         `json:"useOllama"`
-	OllamaModel          string       `json:"ollamaModel"`
-	Hotkey               HotkeyConfig `json:"hotkey"`
-	LLMPrompt            string       `json:"llmPrompt"`
-	FilterHallucinations bool         `json:"filterHallucinations"`
-	PauseMediaOnRecord   bool         `json:"pauseMediaOnRecord"`
-	Microphone           string       `json:"microphone"`
+	WhisperModel          string                 `json:"whisperModel"`
+	TranscriptionBackend  string                 `json:"transcriptionBackend"` // "whisper-cli" (default), "whisper-server" or "openai"
+	TranscriptionURL      string                 `json:"transcriptionUrl"`     // server address; empty uses the backend's default
+	TranscriptionModel    string                 `json:"transcriptionModel"`   // model name for OpenAI-compatible servers
+	TranscriptionAPIKey   string                 `json:"transcriptionApiKey,omitempty"`
+	Language              string                 `json:"language"`             // whisper language code, or "auto" to detect; empty means English
+	Vocabulary            []string               `json:"vocabulary,omitempty"` // names and terms whisper should expect, passed as its initial prompt
+	UseOllama             bool                   `json:"useOllama"`
+	OllamaModel           string                 `json:"ollamaModel"`
+	Hotkey                HotkeyConfig           `json:"hotkey"`
+	CancelHotkey          HotkeyConfig           `json:"cancelHotkey"`
+	LLMPrompt             string                 `json:"llmPrompt"`
+	FilterHallucinations  bool                   `json:"filterHallucinations"`
+	HallucinationPatterns []HallucinationPattern `json:"hallucinationPatterns,omitempty"` // on top of the built-in ones
+	NoSpeechThreshold     float64                `json:"noSpeechThreshold"`               // drop segments whisper rates more likely than this to be silence; 0 uses the default, 1 disables
+	PauseMediaOnRecord    bool                   `json:"pauseMediaOnRecord"`
+	Microphone            string                 `json:"microphone"`
+	AudioBackend          string                 `json:"audioBackend"`      // "auto", "native", "pipewire", "pulse", "alsa" or "sox"
+	HistoryMaxEntries     int                    `json:"historyMaxEntries"` // 0 keeps all entries
+	HistoryMaxAgeDays     int                    `json:"historyMaxAgeDays"` // 0 keeps entries forever
+	AutoStopOnSilence     bool                   `json:"autoStopOnSilence"`
+	SilenceThresholdDB    float64                `json:"silenceThresholdDb"` // dBFS; 0 uses DefaultSilenceThresholdDB
+	SilenceStopSeconds    float64                `json:"silenceStopSeconds"` // 0 uses DefaultSilenceStopSeconds
+	TrimSilence           bool                   `json:"trimSilence"`
+	HighPassFilter        bool                   `json:"highPassFilter"`
+	NoiseReduction        bool                   `json:"noiseReduction"`
+	NormalizeLoudness     bool                   `json:"normalizeLoudness"`
+	PreRollMs             int                    `json:"preRollMs"`           // audio kept from before recording starts; 0 closes the mic between recordings
+	StreamTranscription   bool                   `json:"streamTranscription"` // transcribe chunks cut at pauses while still recording
+	Profiles              []Profile              `json:"profiles,omitempty"`
+}
+
+// HallucinationPattern is a user rule for the hallucination filter. Plain
+// patterns drop segments that say exactly that, ignoring case and
+// punctuation; regex patterns remove every match.
+type HallucinationPattern struct {
+	Pattern string `json:"pattern"`
+	Regex   bool   `json:"regex,omitempty"`
+}
+
+// Profile is an extra dictation hotkey with its own settings. Empty fields
+// fall back to the main settings.
+type Profile struct {
+	Name       string       `json:"name"`
+	Hotkey     HotkeyConfig `json:"hotkey"`
+	Language   string       `json:"language,omitempty"`
+	Vocabulary []string     `json:"vocabulary,omitempty"` // used ahead of the main vocabulary
 }
 
 type TranscriptionEntry struct {
-	Timestamp     int64   `json:"timestamp"`
-	WhisperTime   float64 `json:"whisperTime"`
-	WhisperOutput string  `json:"whisperOutput"`
-	LLMTime       float64 `json:"llmTime"`
-	LLMOutput     string  `json:"llmOutput"`
+	ID               string         `json:"id"`
+	Timestamp        int64          `json:"timestamp"`
+	WhisperTime      float64        `json:"whisperTime"`
+	WhisperOutput    string         `json:"whisperOutput"`
+	LLMTime          float64        `json:"llmTime"`
+	LLMOutput        string         `json:"llmOutput"`
+	WhisperModel     string         `json:"whisperModel"`
+	Language         string         `json:"language,omitempty"`    // language whisper transcribed in, detected for "auto"
+	OllamaModel      string         `json:"ollamaModel,omitempty"` // empty when cleaning was off
+	Profile          string         `json:"profile,omitempty"`     // empty for the default hotkey
+	AudioPath        string         `json:"audioPath,omitempty"`
+	Source           string         `json:"source,omitempty"` // file the audio was imported from; empty for dictations
+	AudioDuration    float64        `json:"audioDuration,omitempty"`
+	RecordedDuration float64        `json:"recordedDuration,omitempty"` // length of the recording as captured, before any preprocessing
+	Segments         []Segment      `json:"segments,omitempty"`
+	Vocabulary       []string       `json:"vocabulary,omitempty"` // terms whisper was prompted with
+	Filtered         []FilteredText `json:"filtered,omitempty"`   // what the hallucination filter removed
+	Revisions        []Revision     `json:"revisions,omitempty"`
+	Error            string         `json:"error,omitempty"` // why the dictation failed; the audio is kept so it can be re-run
 }
 
+// Revision is the result of re-running an entry's audio or text through the
+// pipeline with different settings. The entry's own fields keep the original.
+type Revision struct {
+	Timestamp     int64          `json:"timestamp"`
+	WhisperModel  string         `json:"whisperModel"`
+	Language      string         `json:"language,omitempty"`
+	LLMPrompt     string         `json:"llmPrompt,omitempty"`
+	WhisperTime   float64        `json:"whisperTime"`
+	WhisperOutput string         `json:"whisperOutput"`
+	LLMTime       float64        `json:"llmTime"`
+	LLMOutput     string         `json:"llmOutput"`
+	OllamaModel   string         `json:"ollamaModel,omitempty"`
+	Segments      []Segment      `json:"segments,omitempty"`
+	Vocabulary    []string       `json:"vocabulary,omitempty"`
+	Filtered      []FilteredText `json:"filtered,omitempty"`
+}
+
+// Segment is a timed piece of an entry's whisper output. Times are seconds
+// into the entry's saved audio.
+type Segment struct {
+	Start        float64 `json:"start"`
+	End          float64 `json:"end"`
+	Text         string  `json:"text"`
+	Probability  float64 `json:"probability"`  // average token probability, 0 to 1
+	NoSpeechProb float64 `json:"noSpeechProb"` // whisper's estimate that there was no speech
+}
+
+// FilteredText is a piece of whisper output the hallucination filter
+// removed, and the rule that removed it.
+type FilteredText struct {
+	Text   string  `json:"text"`
+	Reason string  `json:"reason"`
+	Start  float64 `json:"start,omitempty"`
+	End    float64 `json:"end,omitempty"`
+}
+
+// Voice detection defaults. Speech into a typical laptop or headset mic sits
+// well above -45 dBFS, and two seconds is longer than a natural pause.
+const (
+	DefaultSilenceThresholdDB = -45.0
+	DefaultSilenceStopSeconds = 2.0
+)
+
 const DefaultLLMPrompt = `Clean this voice transcript. Output ONLY the cleaned text, nothing else.
 Rules: remove filler words (um, uh, like), fix punctuation and casing, keep original wording.
 Transcript:
@@ -45,10 +142,53 @@ func DefaultConfig() *Config {
 			Modifiers: []string{"cmd", "shift"},
 			Keys:      []string{"r"},
 		},
+		CancelHotkey: HotkeyConfig{
+			Modifiers: []string{"cmd", "shift"},
+			Keys:      []string{"escape"},
+		},
 		LLMPrompt:            DefaultLLMPrompt,
 		FilterHallucinations: true,
 		PauseMediaOnRecord:   true,
+		HistoryMaxEntries:    1000,
+		HistoryMaxAgeDays:    90,
+		SilenceThresholdDB:   DefaultSilenceThresholdDB,
+		SilenceStopSeconds:   DefaultSilenceStopSeconds,
+	}
+}
+
+// SilenceThreshold returns the voice detection threshold in dBFS.
+func (c *Config) SilenceThreshold() float64 {
+	if c.SilenceThresholdDB == 0 {
+		return DefaultSilenceThresholdDB
+	}
+	return c.SilenceThresholdDB
+}
+
+// SilenceStop returns how long the input must stay silent after speech
+// before auto-stop ends the recording, or 0 if auto-stop is off.
+func (c *Config) SilenceStop() time.Duration {
+	if !c.AutoStopOnSilence {
+		return 0
+	}
+	seconds := c.SilenceStopSeconds
+	if seconds <= 0 {
+		seconds = DefaultSilenceStopSeconds
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Profile returns the profile called name. The empty name is the main
+// hotkey, which has no profile.
+func (c *Config) Profile(name string) (Profile, bool) {
+	if name == "" {
+		return Profile{}, false
+	}
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, true
+		}
 	}
+	return Profile{}, false
 }
 
 func ConfigPath() (string, error) {
@@ -73,7 +213,9 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	cfg := &Config{}
+	// Files written before the cancel hotkey existed get the default one;
+	// an empty one in the file turns it off
+	cfg := &Config{CancelHotkey: DefaultConfig().CancelHotkey}
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, data string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaultCancelHotkey(t *testing.T) {
+	writeConfig(t, `{"hotkey": {"modifiers": ["ctrl"], "keys": ["d"]}}`)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := DefaultConfig().CancelHotkey; !reflect.DeepEqual(cfg.CancelHotkey, want) {
+		t.Errorf("cancel hotkey %+v, want the default %+v", cfg.CancelHotkey, want)
+	}
+	if cfg.Hotkey.Keys[0] != "d" {
+		t.Errorf("hotkey %+v not loaded", cfg.Hotkey)
+	}
+}
+
+func TestLoadCancelHotkeyOff(t *testing.T) {
+	writeConfig(t, `{"cancelHotkey": {"modifiers": ["cmd"], "keys": null}}`)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.CancelHotkey.Keys) != 0 {
+		t.Errorf("cancel hotkey %+v, want it off", cfg.CancelHotkey)
+	}
+}
@@ -0,0 +1,162 @@
+// Package control implements the Unix socket protocol used by the jtt CLI to
+// drive a running JTT instance (tray app or headless daemon).
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jtt/internal/logger"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Request is a single command sent by a client. Each connection carries one
+// newline-terminated JSON request followed by one JSON response.
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Response is the reply to a Request.
+type Response struct {
+	OK    bool   `json:"ok"`
+	State string `json:"state,omitempty"`
+	Text  string `json:"text,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// HandlerFunc executes a request and returns its response.
+type HandlerFunc func(req Request) Response
+
+// ErrNotRunning is returned by Send when no JTT instance is listening.
+var ErrNotRunning = errors.New("jtt is not running (start the app or run `jtt daemon`)")
+
+// ErrAlreadyRunning is returned by Listen when another instance is listening.
+var ErrAlreadyRunning = errors.New("another jtt instance is already running")
+
+// SocketPath returns the default control socket location.
+func SocketPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cache", "jtt", "jtt.sock")
+}
+
+type Server struct {
+	listener net.Listener
+	handler  HandlerFunc
+	wg       sync.WaitGroup
+}
+
+// Listen binds the control socket at path and serves requests with handler
+// until Close is called. A stale socket left behind by a crashed instance is
+// removed; a socket with a live listener is an error.
+func Listen(path string, handler HandlerFunc) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w on %s", ErrAlreadyRunning, path)
+		}
+		os.Remove(path)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	s := &Server{listener: l, handler: handler}
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("control: accept failed: %v", err)
+			}
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		logger.Error("control: failed to read request: %v", err)
+		return
+	}
+	if err := json.Unmarshal(line, &req); err != nil {
+		writeResponse(conn, Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	logger.Info("control: received %q", req.Command)
+	writeResponse(conn, s.handler(req))
+}
+
+func writeResponse(conn net.Conn, resp Response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		logger.Error("control: failed to encode response: %v", err)
+		return
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		logger.Error("control: failed to write response: %v", err)
+	}
+}
+
+// Close stops accepting connections and removes the socket file.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// Send delivers a request to the instance listening on path and waits for
+// its response. Commands like "stop" block until transcription finishes, so
+// there is no read deadline.
+func Send(path string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &resp, nil
+}
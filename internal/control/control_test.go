@@ -0,0 +1,51 @@
+package control
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jtt.sock")
+	if _, err := Send(path, Request{Command: "status"}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("sending with nothing listening: got %v, want ErrNotRunning", err)
+	}
+
+	srv, err := Listen(path, func(req Request) Response {
+		return Response{OK: true, State: "idle", Text: req.Command}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Send(path, Request{Command: "status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.OK || resp.State != "idle" || resp.Text != "status" {
+		t.Errorf("got %+v", resp)
+	}
+
+	// A second instance must not take over the socket
+	if _, err := Listen(path, nil); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("listening twice: got %v, want ErrAlreadyRunning", err)
+	}
+	if _, err := Send(path, Request{Command: "status"}); err != nil {
+		t.Errorf("first instance stopped answering: %v", err)
+	}
+	srv.Close()
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jtt.sock")
+	// Left behind by a crashed instance
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := Listen(path, func(req Request) Response { return Response{OK: true} })
+	if err != nil {
+		t.Fatalf("stale socket not replaced: %v", err)
+	}
+	srv.Close()
+}
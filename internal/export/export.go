@@ -0,0 +1,207 @@
+// Package export writes history entries to Markdown, JSON, CSV and subtitle
+// formats.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"jtt/internal/config"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	SRT      Format = "srt"
+	VTT      Format = "vtt"
+)
+
+// ParseFormat accepts a format name or file extension, e.g. "md" or ".csv".
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "markdown", "md":
+		return Markdown, nil
+	case "json":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	case "srt":
+		return SRT, nil
+	case "vtt", "webvtt":
+		return VTT, nil
+	}
+	return "", fmt.Errorf("unknown export format: %s", s)
+}
+
+// FormatFromPath infers the format from a file extension.
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Write exports entries, which should be ordered oldest first.
+func Write(w io.Writer, format Format, entries []config.TranscriptionEntry) error {
+	switch format {
+	case Markdown:
+		return writeMarkdown(w, entries)
+	case JSON:
+		return writeJSON(w, entries)
+	case CSV:
+		return writeCSV(w, entries)
+	case SRT, VTT:
+		return writeSubtitles(w, format, entries)
+	}
+	return fmt.Errorf("unknown export format: %s", format)
+}
+
+// WriteFile exports entries to path, replacing any existing file.
+func WriteFile(path string, format Format, entries []config.TranscriptionEntry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, entries); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// text returns the final text of an entry: the LLM output, or the whisper
+// output when cleaning produced nothing.
+func text(e config.TranscriptionEntry) string {
+	if e.LLMOutput != "" {
+		return e.LLMOutput
+	}
+	return e.WhisperOutput
+}
+
+func writeMarkdown(w io.Writer, entries []config.TranscriptionEntry) error {
+	var b strings.Builder
+	b.WriteString("# Transcriptions\n")
+
+	day := ""
+	for _, e := range entries {
+		t := time.Unix(e.Timestamp, 0)
+		if d := t.Format("Monday, January 2, 2006"); d != day {
+			day = d
+			fmt.Fprintf(&b, "\n## %s\n", day)
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", t.Format("15:04:05"), text(e))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeJSON(w io.Writer, entries []config.TranscriptionEntry) error {
+	if entries == nil {
+		entries = []config.TranscriptionEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func writeCSV(w io.Writer, entries []config.TranscriptionEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"id", "time", "whisperModel", "language", "ollamaModel", "profile", "recordedDuration", "audioDuration",
+		"whisperTime", "whisperOutput", "llmTime", "llmOutput",
+	})
+	for _, e := range entries {
+		cw.Write([]string{
+			e.ID,
+			time.Unix(e.Timestamp, 0).Format(time.RFC3339),
+			e.WhisperModel,
+			e.Language,
+			e.OllamaModel,
+			e.Profile,
+			formatFloat(e.RecordedDuration),
+			formatFloat(e.AudioDuration),
+			formatFloat(e.WhisperTime),
+			e.WhisperOutput,
+			formatFloat(e.LLMTime),
+			e.LLMOutput,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+type cue struct {
+	start, end float64
+	text       string
+}
+
+// cues lays entries end to end on one timeline. Entries with segments get a
+// cue per segment, timed as whisper heard it and with whisper's text; others
+// get one cue spanning the recording with the final text. Entries without a
+// known audio duration are skipped.
+func cues(entries []config.TranscriptionEntry) []cue {
+	var out []cue
+	offset := 0.0
+	for _, e := range entries {
+		if e.AudioDuration <= 0 || text(e) == "" {
+			continue
+		}
+		if len(e.Segments) == 0 {
+			out = append(out, cue{start: offset, end: offset + e.AudioDuration, text: text(e)})
+		}
+		for _, seg := range e.Segments {
+			t := strings.TrimSpace(seg.Text)
+			if t == "" {
+				continue
+			}
+			// Whisper can round the last segment past the end of the audio
+			out = append(out, cue{
+				start: offset + min(seg.Start, e.AudioDuration),
+				end:   offset + min(seg.End, e.AudioDuration),
+				text:  t,
+			})
+		}
+		offset += e.AudioDuration
+	}
+	return out
+}
+
+func writeSubtitles(w io.Writer, format Format, entries []config.TranscriptionEntry) error {
+	cs := cues(entries)
+	if len(cs) == 0 {
+		return fmt.Errorf("no entries have timing information for %s export", format)
+	}
+
+	var b strings.Builder
+	if format == VTT {
+		b.WriteString("WEBVTT\n")
+	}
+	for i, c := range cs {
+		if format == SRT {
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(c.start, ","), timestamp(c.end, ","), c.text)
+		} else {
+			fmt.Fprintf(&b, "\n%s --> %s\n%s\n", timestamp(c.start, "."), timestamp(c.end, "."), c.text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// timestamp formats seconds as HH:MM:SS followed by sep and milliseconds
+func timestamp(seconds float64, sep string) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
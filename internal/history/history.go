@@ -0,0 +1,345 @@
+// Package history persists transcription entries as an append-only JSONL
+// file under the data directory, with each entry's audio kept alongside.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"jtt/internal/config"
+	"jtt/internal/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store keeps every entry in memory, oldest first, and mirrors it to disk.
+// New entries are appended; deletes and retention pruning rewrite the file.
+// All methods are safe for concurrent use.
+type Store struct {
+	mu         sync.Mutex
+	path       string
+	entries    []config.TranscriptionEntry
+	maxEntries int
+	maxAge     time.Duration
+}
+
+// Page is one page of entries, newest first, plus the total entry count.
+type Page struct {
+	Entries []config.TranscriptionEntry `json:"entries"`
+	Total   int                         `json:"total"`
+}
+
+// DefaultPath returns the history file location.
+func DefaultPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "jtt", "history.jsonl")
+}
+
+// Open loads the history file at path, creating it on first write. An empty
+// path gives a store that only lives in memory. maxEntries and maxAgeDays
+// limit retention; zero means unlimited.
+func Open(path string, maxEntries, maxAgeDays int) (*Store, error) {
+	s := &Store{path: path}
+	s.setRetention(maxEntries, maxAgeDays)
+
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry config.TranscriptionEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip a torn write from a crash rather than losing everything
+			logger.Error("history: skipping invalid line %d: %v", line, err)
+			continue
+		}
+		s.entries = append(s.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.prune() {
+		if err := s.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) setRetention(maxEntries, maxAgeDays int) {
+	s.maxEntries = maxEntries
+	s.maxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+}
+
+// SetRetention changes the retention limits and prunes immediately.
+func (s *Store) SetRetention(maxEntries, maxAgeDays int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setRetention(maxEntries, maxAgeDays)
+	if s.prune() {
+		return s.rewrite()
+	}
+	return nil
+}
+
+// Add stores entry, assigning an ID if it has none, and returns the stored
+// entry.
+func (s *Store) Add(entry config.TranscriptionEntry) (config.TranscriptionEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = NewID()
+	}
+	s.entries = append(s.entries, entry)
+
+	if s.prune() {
+		return entry, s.rewrite()
+	}
+	return entry, s.append(entry)
+}
+
+// List returns up to limit entries, newest first, skipping the first offset.
+// A limit of zero or less returns all remaining entries; a negative offset
+// starts from the newest.
+func (s *Store) List(offset, limit int) Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset = max(offset, 0)
+	page := Page{Entries: []config.TranscriptionEntry{}, Total: len(s.entries)}
+	for i := len(s.entries) - 1 - offset; i >= 0; i-- {
+		if limit > 0 && len(page.Entries) == limit {
+			break
+		}
+		page.Entries = append(page.Entries, s.entries[i])
+	}
+	return page
+}
+
+// Select returns the entries with the given IDs, or every entry if ids is
+// empty, oldest first.
+func (s *Store) Select(ids []string) []config.TranscriptionEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(ids) == 0 {
+		return append([]config.TranscriptionEntry(nil), s.entries...)
+	}
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var selected []config.TranscriptionEntry
+	for _, e := range s.entries {
+		if want[e.ID] {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
+
+// Get returns the entry with the given ID.
+func (s *Store) Get(id string) (config.TranscriptionEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return config.TranscriptionEntry{}, false
+}
+
+// AddRevision appends a reprocessing result to the entry with the given ID
+// and returns the updated entry.
+func (s *Store) AddRevision(id string, rev config.Revision) (config.TranscriptionEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.entries {
+		if s.entries[i].ID == id {
+			s.entries[i].Revisions = append(s.entries[i].Revisions, rev)
+			return s.entries[i], s.rewrite()
+		}
+	}
+	return config.TranscriptionEntry{}, fmt.Errorf("history entry not found: %s", id)
+}
+
+// Delete removes the entry with the given ID and its audio.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.ID == id {
+			removeAudio(e)
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return s.rewrite()
+		}
+	}
+	return fmt.Errorf("history entry not found: %s", id)
+}
+
+// Clear removes every entry and its audio.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		removeAudio(e)
+	}
+	s.entries = nil
+	return s.rewrite()
+}
+
+// AudioDir returns the directory holding per-entry recordings, or "" for an
+// in-memory store.
+func (s *Store) AudioDir() string {
+	if s.path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(s.path), "audio")
+}
+
+// KeepAudio moves the recording at src into the audio directory, named after
+// the entry ID, and returns its new path. The recorder reuses its output
+// file, so this must happen before the next recording starts.
+func (s *Store) KeepAudio(id, src string) (string, error) {
+	dir := s.AudioDir()
+	if dir == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dst := filepath.Join(dir, id+filepath.Ext(src))
+	if err := os.Rename(src, dst); err != nil {
+		// Cache and data dirs may be on different volumes
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return "", err
+		}
+		os.Remove(src)
+	}
+	return dst, nil
+}
+
+func removeAudio(e config.TranscriptionEntry) {
+	if e.AudioPath == "" {
+		return
+	}
+	if err := os.Remove(e.AudioPath); err != nil && !os.IsNotExist(err) {
+		logger.Error("history: failed to remove audio %s: %v", e.AudioPath, err)
+	}
+}
+
+// prune drops entries outside the retention limits and reports whether
+// anything was removed. Callers must hold s.mu.
+func (s *Store) prune() bool {
+	before := len(s.entries)
+
+	if s.maxAge > 0 {
+		cutoff := time.Now().Add(-s.maxAge).Unix()
+		kept := s.entries[:0]
+		for _, e := range s.entries {
+			if e.Timestamp >= cutoff {
+				kept = append(kept, e)
+			} else {
+				removeAudio(e)
+			}
+		}
+		s.entries = kept
+	}
+	if s.maxEntries > 0 && len(s.entries) > s.maxEntries {
+		drop := len(s.entries) - s.maxEntries
+		for _, e := range s.entries[:drop] {
+			removeAudio(e)
+		}
+		s.entries = append([]config.TranscriptionEntry(nil), s.entries[drop:]...)
+	}
+
+	return len(s.entries) != before
+}
+
+// append writes a single entry to the end of the file. Callers must hold s.mu.
+func (s *Store) append(entry config.TranscriptionEntry) error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the file with the current entries via a temp file and
+// rename, so a crash never leaves a half-written history. Callers must hold
+// s.mu.
+func (s *Store) rewrite() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, e := range s.entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// NewID returns a random entry ID.
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package history
+
+import (
+	"fmt"
+	"jtt/internal/config"
+	"path/filepath"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := s.Add(config.TranscriptionEntry{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		offset, limit int
+		want          string
+	}{
+		{0, 0, "43210"},
+		{0, 2, "43"},
+		{2, 2, "21"},
+		{4, 10, "0"},
+		{5, 10, ""},
+		{100, 10, ""},
+		{-1, 2, "43"},
+		{-100, 0, "43210"},
+	}
+	for _, tt := range tests {
+		page := s.List(tt.offset, tt.limit)
+		got := ""
+		for _, e := range page.Entries {
+			got += e.ID
+		}
+		if got != tt.want || page.Total != 5 {
+			t.Errorf("List(%d, %d) = %q of %d, want %q of 5", tt.offset, tt.limit, got, page.Total, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package history
+
+import (
+	"jtt/internal/config"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query filters and ranks history entries. Zero-valued fields don't filter.
+type Query struct {
+	Text    string `json:"text"`
+	From    int64  `json:"from"` // unix seconds, inclusive
+	To      int64  `json:"to"`   // unix seconds, inclusive
+	Profile string `json:"profile"`
+	Model   string `json:"model"` // matches the whisper or Ollama model
+	Limit   int    `json:"limit"`
+}
+
+// Fragment is a piece of a snippet; Match marks text that matched the query.
+type Fragment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// Snippet is an excerpt of one entry field around the query matches.
+type Snippet struct {
+	Field     string     `json:"field"` // "whisperOutput" or "llmOutput"
+	Fragments []Fragment `json:"fragments"`
+}
+
+// SearchResult is a matching entry with its relevance score and snippets.
+type SearchResult struct {
+	Entry    config.TranscriptionEntry `json:"entry"`
+	Score    float64                   `json:"score"`
+	Snippets []Snippet                 `json:"snippets"`
+}
+
+// BM25 parameters, plus a bonus for entries containing the query as a phrase
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	phraseBonus = 2.0
+	snippetLen  = 160 // bytes of context around the first match
+)
+
+type token struct {
+	text       string
+	start, end int // byte offsets in the original string
+}
+
+// tokenize splits s into lowercase words of letters and digits, keeping
+// their positions so matches can be highlighted in the original text.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+				b.Reset()
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{text: b.String(), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: b.String(), start: start, end: len(s)})
+	}
+	return tokens
+}
+
+// termMatches reports whether a document token matches a query term. Terms
+// match whole tokens or token prefixes, so "deploy" finds "deployment".
+func termMatches(tok, term string) bool {
+	return strings.HasPrefix(tok, term)
+}
+
+// Search returns entries matching q, best first. Every query term must
+// appear in the whisper or LLM output; an empty query text matches all
+// entries that pass the filters, newest first.
+func (s *Store) Search(q Query) []SearchResult {
+	s.mu.Lock()
+	entries := append([]config.TranscriptionEntry(nil), s.entries...)
+	s.mu.Unlock()
+
+	terms := []string{}
+	for _, t := range tokenize(q.Text) {
+		terms = append(terms, t.text)
+	}
+
+	type doc struct {
+		entry   config.TranscriptionEntry
+		whisper []token
+		llm     []token
+	}
+	var docs []doc
+	totalLen := 0
+	for _, e := range entries {
+		if !matchesFilters(e, q) {
+			continue
+		}
+		d := doc{entry: e, whisper: tokenize(e.WhisperOutput), llm: tokenize(e.LLMOutput)}
+		docs = append(docs, d)
+		totalLen += len(d.whisper) + len(d.llm)
+	}
+
+	results := []SearchResult{}
+	if len(docs) == 0 {
+		return results
+	}
+	avgLen := float64(totalLen) / float64(len(docs))
+
+	// Document frequency per term for IDF
+	df := make(map[string]int, len(terms))
+	for _, d := range docs {
+		for _, term := range terms {
+			if countMatches(d.whisper, term)+countMatches(d.llm, term) > 0 {
+				df[term]++
+			}
+		}
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(q.Text))
+	for _, d := range docs {
+		docLen := float64(len(d.whisper) + len(d.llm))
+		score := 0.0
+		matchedAll := true
+		for _, term := range terms {
+			tf := float64(countMatches(d.whisper, term) + countMatches(d.llm, term))
+			if tf == 0 {
+				matchedAll = false
+				break
+			}
+			n := float64(len(docs))
+			idf := math.Log(1 + (n-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*docLen/avgLen))
+		}
+		if !matchedAll {
+			continue
+		}
+		if len(terms) > 1 && (strings.Contains(strings.ToLower(d.entry.WhisperOutput), phrase) ||
+			strings.Contains(strings.ToLower(d.entry.LLMOutput), phrase)) {
+			score += phraseBonus
+		}
+
+		result := SearchResult{Entry: d.entry, Score: score, Snippets: []Snippet{}}
+		if len(terms) > 0 {
+			if sn, ok := snippet("llmOutput", d.entry.LLMOutput, d.llm, terms); ok {
+				result.Snippets = append(result.Snippets, sn)
+			}
+			if sn, ok := snippet("whisperOutput", d.entry.WhisperOutput, d.whisper, terms); ok {
+				result.Snippets = append(result.Snippets, sn)
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.Timestamp > results[j].Entry.Timestamp
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+func matchesFilters(e config.TranscriptionEntry, q Query) bool {
+	if q.From > 0 && e.Timestamp < q.From {
+		return false
+	}
+	if q.To > 0 && e.Timestamp > q.To {
+		return false
+	}
+	if q.Profile != "" && e.Profile != q.Profile {
+		return false
+	}
+	if q.Model != "" && e.WhisperModel != q.Model && e.OllamaModel != q.Model {
+		return false
+	}
+	return true
+}
+
+func countMatches(tokens []token, term string) int {
+	n := 0
+	for _, t := range tokens {
+		if termMatches(t.text, term) {
+			n++
+		}
+	}
+	return n
+}
+
+// snippet builds an excerpt of text centred on the first matching token,
+// with every match inside the excerpt marked.
+func snippet(field, text string, tokens []token, terms []string) (Snippet, bool) {
+	var matches []token
+	for _, t := range tokens {
+		for _, term := range terms {
+			if termMatches(t.text, term) {
+				matches = append(matches, t)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return Snippet{}, false
+	}
+
+	// Window around the first match, widened to token boundaries
+	start := matches[0].start - snippetLen/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetLen
+	if end > len(text) {
+		end = len(text)
+	}
+	for _, t := range tokens {
+		if t.start < start && t.end > start {
+			start = t.start
+		}
+		if t.start < end && t.end > end {
+			end = t.end
+		}
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	sn := Snippet{Field: field}
+	if start > 0 {
+		sn.Fragments = append(sn.Fragments, Fragment{Text: "…"})
+	}
+	pos := start
+	for _, m := range matches {
+		if m.start < start || m.end > end {
+			continue
+		}
+		if m.start > pos {
+			sn.Fragments = append(sn.Fragments, Fragment{Text: text[pos:m.start]})
+		}
+		sn.Fragments = append(sn.Fragments, Fragment{Text: text[m.start:m.end], Match: true})
+		pos = m.end
+	}
+	if pos < end {
+		sn.Fragments = append(sn.Fragments, Fragment{Text: text[pos:end]})
+	}
+	if end < len(text) {
+		sn.Fragments = append(sn.Fragments, Fragment{Text: "…"})
+	}
+	return sn, true
+}
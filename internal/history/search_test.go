@@ -0,0 +1,133 @@
+package history
+
+import (
+	"jtt/internal/config"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func searchStore(t *testing.T, entries ...config.TranscriptionEntry) *Store {
+	t.Helper()
+	s, err := Open("", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if _, err := s.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func ids(results []SearchResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		b.WriteString(r.Entry.ID)
+	}
+	return b.String()
+}
+
+func TestSearchRanking(t *testing.T) {
+	s := searchStore(t,
+		config.TranscriptionEntry{ID: "a", Timestamp: 1, LLMOutput: "Lunch is at noon."},
+		config.TranscriptionEntry{ID: "b", Timestamp: 2, LLMOutput: "Fix the deploy script, then check the deploy logs."},
+		config.TranscriptionEntry{ID: "c", Timestamp: 3, LLMOutput: "The deployment went out with the release notes attached to the ticket."},
+		config.TranscriptionEntry{ID: "d", Timestamp: 4, WhisperOutput: "deploy the fix", LLMOutput: "Ship it."},
+	)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		// More and denser matches rank higher; prefixes match longer words
+		{"deploy", "bdc"},
+		// The exact phrase beats the same words apart
+		{"the fix", "db"},
+		// Every term must match
+		{"deploy lunch", ""},
+		{"dinner", ""},
+		// No text lists everything, newest first
+		{"", "dcba"},
+	}
+	for _, tt := range tests {
+		if got := ids(s.Search(Query{Text: tt.text})); got != tt.want {
+			t.Errorf("Search(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if got := ids(s.Search(Query{Text: "deploy", Limit: 2})); got != "bd" {
+		t.Errorf("limited search = %q, want %q", got, "bd")
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	s := searchStore(t,
+		config.TranscriptionEntry{ID: "a", Timestamp: 100, Profile: "work", WhisperModel: "base", LLMOutput: "notes"},
+		config.TranscriptionEntry{ID: "b", Timestamp: 200, Profile: "mail", WhisperModel: "base", OllamaModel: "llama", LLMOutput: "notes"},
+		config.TranscriptionEntry{ID: "c", Timestamp: 300, Profile: "work", WhisperModel: "large", LLMOutput: "notes"},
+	)
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"profile", Query{Profile: "work"}, "ca"},
+		{"whisper model", Query{Model: "base"}, "ba"},
+		{"Ollama model", Query{Model: "llama"}, "b"},
+		{"from", Query{From: 200}, "cb"},
+		{"to", Query{To: 200}, "ba"},
+		{"range", Query{From: 150, To: 250}, "b"},
+		{"text and profile", Query{Text: "notes", Profile: "mail"}, "b"},
+		{"unknown profile", Query{Profile: "home"}, ""},
+	}
+	for _, tt := range tests {
+		if got := ids(s.Search(tt.q)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	s := searchStore(t, config.TranscriptionEntry{
+		ID:            "a",
+		WhisperOutput: "please deploy it",
+		LLMOutput:     "Please deploy it.",
+	})
+	results := s.Search(Query{Text: "deploy"})
+	if len(results) != 1 || len(results[0].Snippets) != 2 {
+		t.Fatalf("got %+v", results)
+	}
+	sn := results[0].Snippets[0]
+	if sn.Field != "llmOutput" || len(sn.Fragments) != 3 {
+		t.Fatalf("snippet %+v", sn)
+	}
+	if f := sn.Fragments[1]; f.Text != "deploy" || !f.Match {
+		t.Errorf("match fragment %+v", f)
+	}
+}
+
+// TestSearchSnippetMultibyte checks that snippets of long text full of
+// multi-byte punctuation are never cut mid-character, whichever byte the
+// window ends on.
+func TestSearchSnippetMultibyte(t *testing.T) {
+	for pad := 0; pad < 4; pad++ {
+		text := strings.Repeat(" ", pad) + "deploy" + strings.Repeat(" — ✓", 100)
+		s := searchStore(t, config.TranscriptionEntry{ID: "a", LLMOutput: text})
+		results := s.Search(Query{Text: "deploy"})
+		if len(results) != 1 || len(results[0].Snippets) != 1 {
+			t.Fatalf("pad %d: got %+v", pad, results)
+		}
+		sn := results[0].Snippets[0]
+		if last := sn.Fragments[len(sn.Fragments)-1]; last.Text != "…" {
+			t.Errorf("pad %d: long text not cut off: %+v", pad, last)
+		}
+		for _, f := range sn.Fragments {
+			if !utf8.ValidString(f.Text) {
+				t.Errorf("pad %d: fragment %q is not valid UTF-8", pad, f.Text)
+			}
+		}
+	}
+}
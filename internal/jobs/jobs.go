@@ -0,0 +1,291 @@
+// Package jobs queues finished recordings for transcription, so a new
+// dictation can start while earlier ones are still being processed. Jobs
+// run one at a time in the order they were added, which keeps delivery in
+// order.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"jtt/internal/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+// Stages a job passes through. While running, a job reports the stages of
+// the pipeline processing it, e.g. "transcribing".
+const (
+	Queued    = "queued"
+	Done      = "done"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+)
+
+// keepFinished is how many finished jobs List keeps reporting.
+const keepFinished = 10
+
+// Job is one recording waiting for or going through the pipeline. Its audio
+// file belongs to the job: RunFunc moves it somewhere permanent, even if the
+// job fails, and the queue deletes it if the job is cancelled.
+type Job struct {
+	ID        string `json:"id"`
+	Created   int64  `json:"created"`
+	AudioPath string `json:"audioPath"`
+	Profile   string `json:"profile,omitempty"` // profile the recording was made with
+	Stage     string `json:"stage"`
+	Text      string `json:"text,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Finished reports whether the job has left the queue.
+func (j Job) Finished() bool {
+	return j.Stage == Done || j.Stage == Failed || j.Stage == Cancelled
+}
+
+// RunFunc processes a job and returns the delivered text. It reports
+// progress by calling stage and must stop when ctx is cancelled.
+type RunFunc func(ctx context.Context, job Job, stage func(string)) (string, error)
+
+type task struct {
+	job    Job
+	cancel context.CancelFunc // set while running
+	done   chan struct{}      // closed when finished
+}
+
+// Queue runs jobs through a RunFunc on a single worker goroutine.
+type Queue struct {
+	mu       sync.Mutex
+	tasks    []*task // in the order added, including recently finished ones
+	running  bool
+	run      RunFunc
+	onChange func([]Job)
+	onIdle   func()
+}
+
+// New returns an empty queue. onChange, if non-nil, receives the job list
+// after every change; onIdle, if non-nil, is called when the last queued job
+// has finished. Both are called without the queue locked.
+func New(run RunFunc, onChange func([]Job), onIdle func()) *Queue {
+	return &Queue{run: run, onChange: onChange, onIdle: onIdle}
+}
+
+// Add queues the recording at audioPath, made with profile, under the given
+// ID.
+func (q *Queue) Add(id, audioPath, profile string) Job {
+	q.mu.Lock()
+	t := &task{
+		job: Job{
+			ID:        id,
+			Created:   time.Now().Unix(),
+			AudioPath: audioPath,
+			Profile:   profile,
+			Stage:     Queued,
+		},
+		done: make(chan struct{}),
+	}
+	q.tasks = append(q.tasks, t)
+	if !q.running {
+		q.running = true
+		go q.work()
+	}
+	q.mu.Unlock()
+
+	logger.Info("jobs: queued %s", id)
+	q.changed()
+	return t.job
+}
+
+// List returns recently finished and unfinished jobs, oldest first.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.list()
+}
+
+func (q *Queue) list() []Job {
+	list := make([]Job, len(q.tasks))
+	for i, t := range q.tasks {
+		list[i] = t.job
+	}
+	return list
+}
+
+// Pending returns the number of unfinished jobs.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, t := range q.tasks {
+		if !t.job.Finished() {
+			n++
+		}
+	}
+	return n
+}
+
+// Wait blocks until the job with the given ID has finished and returns it.
+func (q *Queue) Wait(id string) (Job, error) {
+	q.mu.Lock()
+	t := q.find(id)
+	q.mu.Unlock()
+	if t == nil {
+		return Job{}, errors.New("job not found: " + id)
+	}
+
+	<-t.done
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return t.job, nil
+}
+
+// Cancel stops a queued or running job. It returns false if the job is
+// unknown or already finished.
+func (q *Queue) Cancel(id string) bool {
+	q.mu.Lock()
+	t := q.find(id)
+	ok := t != nil && q.cancel(t)
+	q.mu.Unlock()
+
+	if ok {
+		q.changed()
+	}
+	return ok
+}
+
+// CancelAll stops every queued and running job.
+func (q *Queue) CancelAll() {
+	q.mu.Lock()
+	n := 0
+	for _, t := range q.tasks {
+		if q.cancel(t) {
+			n++
+		}
+	}
+	q.mu.Unlock()
+
+	if n > 0 {
+		q.changed()
+	}
+}
+
+// cancel must be called with q.mu held. A running job is finished by the
+// worker once its RunFunc returns.
+func (q *Queue) cancel(t *task) bool {
+	switch {
+	case t.job.Finished():
+		return false
+	case t.cancel != nil:
+		t.cancel()
+	default:
+		q.finish(t, Cancelled, "", nil)
+	}
+	logger.Info("jobs: cancelled %s", t.job.ID)
+	return true
+}
+
+func (q *Queue) find(id string) *task {
+	for _, t := range q.tasks {
+		if t.job.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// work runs queued jobs in order until none are left.
+func (q *Queue) work() {
+	for {
+		q.mu.Lock()
+		t := q.next()
+		if t == nil {
+			q.running = false
+			q.mu.Unlock()
+			if q.onIdle != nil {
+				q.onIdle()
+			}
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		t.cancel = cancel
+		job := t.job
+		q.mu.Unlock()
+
+		text, err := q.run(ctx, job, func(stage string) {
+			q.mu.Lock()
+			if !t.job.Finished() {
+				t.job.Stage = stage
+			}
+			q.mu.Unlock()
+			q.changed()
+		})
+
+		q.mu.Lock()
+		switch {
+		case ctx.Err() != nil:
+			q.finish(t, Cancelled, "", nil)
+		case err != nil:
+			logger.Error("jobs: %s failed: %v", job.ID, err)
+			q.finish(t, Failed, "", err)
+		default:
+			q.finish(t, Done, text, nil)
+		}
+		cancel()
+		q.mu.Unlock()
+		q.changed()
+	}
+}
+
+// next returns the oldest queued job. It must be called with q.mu held.
+func (q *Queue) next() *task {
+	for _, t := range q.tasks {
+		if t.job.Stage == Queued {
+			return t
+		}
+	}
+	return nil
+}
+
+// finish records a job's outcome, releases its waiters and drops the oldest
+// finished jobs beyond keepFinished. It must be called with q.mu held.
+func (q *Queue) finish(t *task, stage, text string, err error) {
+	t.job.Stage = stage
+	t.job.Text = text
+	if err != nil {
+		t.job.Error = err.Error()
+	}
+	t.cancel = nil
+	close(t.done)
+
+	if stage == Cancelled {
+		if err := os.Remove(t.job.AudioPath); err != nil && !os.IsNotExist(err) {
+			logger.Error("jobs: failed to remove audio %s: %v", t.job.AudioPath, err)
+		}
+	}
+
+	finished := 0
+	for _, t := range q.tasks {
+		if t.job.Finished() {
+			finished++
+		}
+	}
+	kept := q.tasks[:0]
+	for _, t := range q.tasks {
+		if t.job.Finished() && finished > keepFinished {
+			finished--
+			continue
+		}
+		kept = append(kept, t)
+	}
+	q.tasks = kept
+}
+
+func (q *Queue) changed() {
+	if q.onChange == nil {
+		return
+	}
+	q.mu.Lock()
+	list := q.list()
+	q.mu.Unlock()
+	q.onChange(list)
+}
@@ -0,0 +1,249 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// audioFile creates a stand-in recording for a job.
+func audioFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".wav")
+	if err := os.WriteFile(path, []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func wait(t *testing.T, q *Queue, id string) Job {
+	t.Helper()
+	job, err := q.Wait(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestOrder(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	q := New(func(ctx context.Context, job Job, stage func(string)) (string, error) {
+		stage("transcribing")
+		mu.Lock()
+		ran = append(ran, job.ID)
+		mu.Unlock()
+		return "text " + job.ID, nil
+	}, nil, nil)
+
+	ids := []string{"a", "b", "c", "d"}
+	for _, id := range ids {
+		if job := q.Add(id, audioFile(t, id), "p"); job.Stage != Queued || job.Profile != "p" {
+			t.Errorf("added %+v", job)
+		}
+	}
+	for _, id := range ids {
+		if job := wait(t, q, id); job.Stage != Done || job.Text != "text "+id {
+			t.Errorf("job %s ended %+v", id, job)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(ran) != fmt.Sprint(ids) {
+		t.Errorf("ran %v, want %v", ran, ids)
+	}
+	if q.Pending() != 0 {
+		t.Errorf("%d jobs still pending", q.Pending())
+	}
+}
+
+// blockingRun runs jobs until they are released or cancelled, and reports
+// each job it starts on started.
+type blockingRun struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingRun() *blockingRun {
+	return &blockingRun{started: make(chan string, 10), release: make(chan struct{})}
+}
+
+func (b *blockingRun) run(ctx context.Context, job Job, stage func(string)) (string, error) {
+	b.started <- job.ID
+	select {
+	case <-b.release:
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	b := newBlockingRun()
+	q := New(b.run, nil, nil)
+
+	q.Add("running", audioFile(t, "running"), "")
+	<-b.started
+	queued := audioFile(t, "queued")
+	q.Add("queued", queued, "")
+
+	if !q.Cancel("queued") {
+		t.Fatal("queued job not cancelled")
+	}
+	if job := wait(t, q, "queued"); job.Stage != Cancelled {
+		t.Errorf("cancelled job ended %s", job.Stage)
+	}
+	if exists(queued) {
+		t.Error("cancelled job's audio left behind")
+	}
+	if q.Cancel("queued") || q.Cancel("unknown") {
+		t.Error("cancelled a finished or unknown job")
+	}
+
+	// The running job carries on
+	close(b.release)
+	if job := wait(t, q, "running"); job.Stage != Done {
+		t.Errorf("running job ended %s", job.Stage)
+	}
+	select {
+	case id := <-b.started:
+		t.Errorf("cancelled job %s ran", id)
+	default:
+	}
+}
+
+func TestCancelRunning(t *testing.T) {
+	b := newBlockingRun()
+	q := New(b.run, nil, nil)
+
+	path := audioFile(t, "a")
+	q.Add("a", path, "")
+	<-b.started
+	if !q.Cancel("a") {
+		t.Fatal("running job not cancelled")
+	}
+	if job := wait(t, q, "a"); job.Stage != Cancelled {
+		t.Errorf("job ended %s", job.Stage)
+	}
+	if exists(path) {
+		t.Error("cancelled job's audio left behind")
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	b := newBlockingRun()
+	q := New(b.run, nil, nil)
+
+	for _, id := range []string{"a", "b", "c"} {
+		q.Add(id, audioFile(t, id), "")
+	}
+	<-b.started
+	q.CancelAll()
+	for _, id := range []string{"a", "b", "c"} {
+		if job := wait(t, q, id); job.Stage != Cancelled {
+			t.Errorf("job %s ended %s", id, job.Stage)
+		}
+	}
+	if q.Pending() != 0 {
+		t.Errorf("%d jobs still pending", q.Pending())
+	}
+}
+
+func TestFailedKeepsAudio(t *testing.T) {
+	q := New(func(ctx context.Context, job Job, stage func(string)) (string, error) {
+		return "", errors.New("whisper crashed")
+	}, nil, nil)
+
+	path := audioFile(t, "a")
+	q.Add("a", path, "")
+	job := wait(t, q, "a")
+	if job.Stage != Failed || job.Error != "whisper crashed" {
+		t.Errorf("job ended %+v", job)
+	}
+	if !exists(path) {
+		t.Error("failed job's audio was deleted")
+	}
+}
+
+func TestWaitUnknown(t *testing.T) {
+	q := New(nil, nil, nil)
+	if _, err := q.Wait("missing"); err == nil {
+		t.Error("waited on an unknown job")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	q := New(func(ctx context.Context, job Job, stage func(string)) (string, error) {
+		return "", nil
+	}, nil, nil)
+
+	n := keepFinished + 5
+	for i := 0; i < n; i++ {
+		id := fmt.Sprint(i)
+		q.Add(id, audioFile(t, id), "")
+		wait(t, q, id)
+	}
+	list := q.List()
+	if len(list) != keepFinished {
+		t.Fatalf("listed %d jobs, want %d", len(list), keepFinished)
+	}
+	if list[0].ID != fmt.Sprint(n-keepFinished) || list[len(list)-1].ID != fmt.Sprint(n-1) {
+		t.Errorf("kept %s to %s, want the newest", list[0].ID, list[len(list)-1].ID)
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	b := newBlockingRun()
+	idle := make(chan struct{}, 10)
+	var mu sync.Mutex
+	var stages []string
+	q := New(b.run, func(list []Job) {
+		mu.Lock()
+		defer mu.Unlock()
+		stages = append(stages, list[len(list)-1].Stage)
+	}, func() { idle <- struct{}{} })
+
+	q.Add("a", audioFile(t, "a"), "")
+	q.Add("b", audioFile(t, "b"), "")
+	<-b.started
+	select {
+	case <-idle:
+		t.Fatal("idle with jobs still queued")
+	default:
+	}
+
+	close(b.release)
+	select {
+	case <-idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("onIdle not called once the queue emptied")
+	}
+	select {
+	case <-idle:
+		t.Error("onIdle called more than once")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	if len(stages) == 0 || stages[len(stages)-1] != Done {
+		t.Errorf("last change reported %v, want done", stages)
+	}
+	mu.Unlock()
+
+	// The worker starts again for the next job
+	q.Add("c", audioFile(t, "c"), "")
+	if job := wait(t, q, "c"); job.Stage != Done {
+		t.Errorf("job added after idle ended %s", job.Stage)
+	}
+}
@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"context"
+	"jtt/internal/logger"
+	"jtt/internal/transcriber"
+	"time"
+)
+
+// AudioSource captures a recording and reports where the audio was written.
+// Recording is driven separately from the rest of the pipeline, so that a
+// new recording can start while earlier ones are still being processed.
+type AudioSource interface {
+	Start() error
+	Stop() error
+	AudioPath() string
+}
+
+// AudioFilter rewrites a recorded audio file in place before transcription,
+// e.g. to trim silence.
+type AudioFilter interface {
+	Filter(audioPath string) error
+}
+
+// Transcriber turns a recorded audio file into text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error)
+}
+
+// Processor rewrites transcribed text, e.g. LLM cleaning. Processors run in
+// order, each receiving the previous one's output.
+type Processor interface {
+	Process(ctx context.Context, text string) (string, error)
+}
+
+// Sink delivers the final text, e.g. to the clipboard or the focused window.
+type Sink interface {
+	Deliver(text string) error
+}
+
+// Stage names a step of a pipeline run, as reported to OnStage.
+type Stage string
+
+const (
+	StageTranscribing Stage = "transcribing"
+	StageProcessing   Stage = "processing"
+	StageDelivering   Stage = "delivering"
+)
+
+// Result holds the output and timings of a pipeline run.
+type Result struct {
+	WhisperText    string
+	WhisperSeconds float64
+	Language       string // language whisper transcribed in; empty for ProcessText
+	Segments       []transcriber.Segment
+	Vocabulary     []string               // terms whisper was prompted with
+	Filtered       []transcriber.Filtered // what the hallucination filter removed
+	Text           string
+	ProcessSeconds float64
+}
+
+// Pipeline wires audio filters, a transcription backend, a text processor
+// chain and output sinks into the flow that turns a recording into text.
+type Pipeline struct {
+	AudioFilters []AudioFilter
+	Transcriber  Transcriber
+	Processors   []Processor
+	Sinks        []Sink
+
+	// OnStage, if set, is called as the run moves through each stage
+	OnStage func(Stage)
+}
+
+// FilterAudio runs a freshly recorded file through the audio filters.
+func (p *Pipeline) FilterAudio(audioPath string) {
+	for _, f := range p.AudioFilters {
+		// A failing filter leaves the audio untouched
+		if err := f.Filter(audioPath); err != nil {
+			logger.Error("Audio filter failed: %v", err)
+		}
+	}
+}
+
+func (p *Pipeline) stage(s Stage) {
+	if p.OnStage != nil {
+		p.OnStage(s)
+	}
+}
+
+// Process transcribes audioPath, runs the text through the processor chain
+// and hands the result to every sink. If ctx is cancelled, the running stage
+// is aborted and nothing is delivered.
+func (p *Pipeline) Process(ctx context.Context, audioPath string) (*Result, error) {
+	p.stage(StageTranscribing)
+	whisperResult, err := p.Transcriber.Transcribe(ctx, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Transcription completed in %.2fs", whisperResult.Seconds)
+
+	result, err := p.ProcessText(ctx, whisperResult.Text)
+	if err != nil {
+		return nil, err
+	}
+	result.WhisperSeconds = whisperResult.Seconds
+	result.Language = whisperResult.Language
+	result.Segments = whisperResult.Segments
+	result.Vocabulary = whisperResult.Vocabulary
+	result.Filtered = whisperResult.Filtered
+	return result, nil
+}
+
+// ProcessText runs already-transcribed text through the processor chain and
+// sinks, skipping transcription.
+func (p *Pipeline) ProcessText(ctx context.Context, text string) (*Result, error) {
+	result := &Result{WhisperText: text, Text: text}
+
+	// Skip processing if there's no text
+	if result.Text != "" && len(p.Processors) > 0 {
+		p.stage(StageProcessing)
+		start := time.Now()
+		for _, proc := range p.Processors {
+			text, err := proc.Process(ctx, result.Text)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				// A failing processor leaves the text untouched
+				logger.Error("Text processing failed: %v", err)
+				continue
+			}
+			result.Text = text
+		}
+		result.ProcessSeconds = time.Since(start).Seconds()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.stage(StageDelivering)
+	for _, sink := range p.Sinks {
+		if err := sink.Deliver(result.Text); err != nil {
+			logger.Error("Failed to deliver output: %v", err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"jtt/internal/transcriber"
+)
+
+type fakeFilter struct {
+	err   error
+	paths []string
+}
+
+func (f *fakeFilter) Filter(audioPath string) error {
+	f.paths = append(f.paths, audioPath)
+	return f.err
+}
+
+type fakeTranscriber struct {
+	result *transcriber.TranscribeResult
+	err    error
+}
+
+func (f fakeTranscriber) Transcribe(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.result, nil
+}
+
+type processorFunc func(ctx context.Context, text string) (string, error)
+
+func (f processorFunc) Process(ctx context.Context, text string) (string, error) {
+	return f(ctx, text)
+}
+
+type fakeSink struct {
+	delivered []string
+}
+
+func (s *fakeSink) Deliver(text string) error {
+	s.delivered = append(s.delivered, text)
+	return nil
+}
+
+func TestProcess(t *testing.T) {
+	sink := &fakeSink{}
+	var stages []Stage
+	p := &Pipeline{
+		Transcriber: fakeTranscriber{result: &transcriber.TranscribeResult{
+			Text:     "hello world",
+			Seconds:  1.5,
+			Language: "en",
+			Segments: []transcriber.Segment{{Start: 0, End: 1, Text: " hello world"}},
+		}},
+		Processors: []Processor{
+			processorFunc(func(ctx context.Context, text string) (string, error) {
+				return strings.ToUpper(text), nil
+			}),
+			// A failing processor leaves the text as it was
+			processorFunc(func(ctx context.Context, text string) (string, error) {
+				return "", errors.New("ollama down")
+			}),
+			processorFunc(func(ctx context.Context, text string) (string, error) {
+				return text + ".", nil
+			}),
+		},
+		Sinks:   []Sink{sink},
+		OnStage: func(s Stage) { stages = append(stages, s) },
+	}
+
+	result, err := p.Process(context.Background(), "rec.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "HELLO WORLD." || result.WhisperText != "hello world" {
+		t.Errorf("got text %q from %q", result.Text, result.WhisperText)
+	}
+	if result.WhisperSeconds != 1.5 || result.Language != "en" || len(result.Segments) != 1 {
+		t.Errorf("transcription details not kept: %+v", result)
+	}
+	if want := []string{"HELLO WORLD."}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("delivered %q, want %q", sink.delivered, want)
+	}
+	if want := []Stage{StageTranscribing, StageProcessing, StageDelivering}; !reflect.DeepEqual(stages, want) {
+		t.Errorf("stages %v, want %v", stages, want)
+	}
+}
+
+func TestProcessEmptyTranscription(t *testing.T) {
+	sink := &fakeSink{}
+	p := &Pipeline{
+		Transcriber: fakeTranscriber{result: &transcriber.TranscribeResult{}},
+		Processors: []Processor{processorFunc(func(ctx context.Context, text string) (string, error) {
+			t.Error("processor ran on empty text")
+			return text, nil
+		})},
+		Sinks: []Sink{sink},
+	}
+	if _, err := p.Process(context.Background(), "rec.wav"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{""}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("delivered %q, want %q", sink.delivered, want)
+	}
+}
+
+func TestProcessTranscriptionError(t *testing.T) {
+	sink := &fakeSink{}
+	failure := errors.New("whisper crashed")
+	p := &Pipeline{
+		Transcriber: fakeTranscriber{err: failure},
+		Sinks:       []Sink{sink},
+	}
+	if _, err := p.Process(context.Background(), "rec.wav"); !errors.Is(err, failure) {
+		t.Fatalf("got error %v, want %v", err, failure)
+	}
+	if len(sink.delivered) > 0 {
+		t.Errorf("delivered %q after a failed transcription", sink.delivered)
+	}
+}
+
+func TestProcessCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := &fakeSink{}
+	p := &Pipeline{
+		Transcriber: fakeTranscriber{result: &transcriber.TranscribeResult{Text: "hello"}},
+		Processors: []Processor{processorFunc(func(ctx context.Context, text string) (string, error) {
+			cancel()
+			return text, nil
+		})},
+		Sinks: []Sink{sink},
+	}
+	if _, err := p.Process(ctx, "rec.wav"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(sink.delivered) > 0 {
+		t.Errorf("delivered %q after cancelling", sink.delivered)
+	}
+}
+
+func TestFilterAudio(t *testing.T) {
+	failing := &fakeFilter{err: errors.New("bad wav")}
+	next := &fakeFilter{}
+	p := &Pipeline{AudioFilters: []AudioFilter{failing, next}}
+	p.FilterAudio("rec.wav")
+	if len(failing.paths) != 1 || len(next.paths) != 1 {
+		t.Errorf("a failing filter should not stop the others: ran %v and %v", failing.paths, next.paths)
+	}
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"fmt"
+	"jtt/internal/logger"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Clipboard copies the text to the system clipboard using pbcopy on macOS,
+// and wl-copy, xclip or xsel on Linux.
+type Clipboard struct{}
+
+func (Clipboard) Deliver(text string) error {
+	command, err := clipboardCommand()
+	if err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	logger.Info("Copied to clipboard: %d chars", len(text))
+	return nil
+}
+
+// Paste sends the paste shortcut to the focused window. It expects the text
+// to already be on the clipboard, so it belongs after Clipboard in the sink
+// list.
+type Paste struct{}
+
+func (Paste) Deliver(text string) error {
+	// Small delay to ensure clipboard is ready
+	time.Sleep(50 * time.Millisecond)
+
+	command, err := pasteCommand()
+	if err != nil {
+		return fmt.Errorf("failed to paste: %w", err)
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to paste: %w, output: %s", err, string(output))
+	}
+	logger.Info("Paste command executed")
+	return nil
+}
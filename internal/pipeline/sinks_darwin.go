@@ -0,0 +1,9 @@
+package pipeline
+
+func clipboardCommand() ([]string, error) {
+	return []string{"pbcopy"}, nil
+}
+
+func pasteCommand() ([]string, error) {
+	return []string{"osascript", "-e", `tell application "System Events" to keystroke "v" using command down`}, nil
+}
@@ -0,0 +1,44 @@
+//go:build !darwin
+
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func clipboardCommand() ([]string, error) {
+	return findCommand(
+		[][]string{{"wl-copy"}},
+		[][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}},
+	)
+}
+
+func pasteCommand() ([]string, error) {
+	return findCommand(
+		// ydotool takes evdev key codes: 29 is left Ctrl, 47 is V
+		[][]string{{"wtype", "-M", "ctrl", "v", "-m", "ctrl"}, {"ydotool", "key", "29:1", "47:1", "47:0", "29:0"}},
+		[][]string{{"xdotool", "key", "--clearmodifiers", "ctrl+v"}},
+	)
+}
+
+// findCommand returns the first installed command, trying the Wayland ones
+// first in a Wayland session. X11 tools still reach XWayland windows there.
+func findCommand(wayland, x11 [][]string) ([]string, error) {
+	var candidates [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, wayland...)
+	}
+	candidates = append(candidates, x11...)
+
+	var names []string
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return c, nil
+		}
+		names = append(names, c[0])
+	}
+	return nil, fmt.Errorf("none of %s is installed", strings.Join(names, ", "))
+}
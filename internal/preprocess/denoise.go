@@ -0,0 +1,144 @@
+package preprocess
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Denoise reduces steady background noise (fans, hum, hiss) by spectral
+// subtraction. The noise spectrum is estimated from the quietest frames of
+// the recording itself, so no separate noise sample is needed.
+type Denoise struct {
+	// Strength scales the subtracted noise estimate; 1 removes the
+	// average noise level, higher values remove more at the cost of
+	// "musical" artifacts.
+	Strength float64
+	// Floor is the minimum gain per frequency bin, which keeps some
+	// ambience so speech doesn't sound gated.
+	Floor float64
+}
+
+const (
+	fftSize = 512 // 32ms at 16 kHz
+	hopSize = fftSize / 2
+	// noiseFraction of the quietest frames make up the noise estimate
+	noiseFraction  = 0.1
+	minNoiseFrames = 5
+)
+
+func (Denoise) Name() string { return "noise reduction" }
+
+func (d Denoise) Apply(samples []float64) []float64 {
+	frames := (len(samples) - fftSize) / hopSize
+	if frames < minNoiseFrames*2 {
+		return samples
+	}
+
+	// sqrt-Hann analysis and synthesis windows overlap-add to unity at 50%
+	window := make([]float64, fftSize)
+	for i := range window {
+		window[i] = math.Sqrt(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/fftSize))
+	}
+
+	// First pass: rank frames by energy, which the windowed samples give
+	// without a transform, and average the power spectrum of the quietest
+	energy := make([]float64, frames)
+	for f := range energy {
+		for i, w := range window {
+			x := samples[f*hopSize+i] * w
+			energy[f] += x * x
+		}
+	}
+	order := make([]int, frames)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return energy[order[a]] < energy[order[b]] })
+	n := max(int(float64(frames)*noiseFraction), minNoiseFrames)
+	buf := make([]complex128, fftSize)
+	noise := make([]float64, fftSize/2+1)
+	for _, f := range order[:n] {
+		analyse(buf, samples[f*hopSize:], window)
+		for k := range noise {
+			noise[k] += power(buf[k]) / float64(n)
+		}
+	}
+
+	// Second pass: subtract the noise one frame at a time
+	out := make([]float64, len(samples))
+	for f := 0; f < frames; f++ {
+		analyse(buf, samples[f*hopSize:], window)
+		for k := 0; k <= fftSize/2; k++ {
+			p := power(buf[k])
+			gain := d.Floor
+			if p > 0 {
+				gain = math.Max(math.Sqrt(math.Max(1-d.Strength*noise[k]/p, 0)), d.Floor)
+			}
+			buf[k] *= complex(gain, 0)
+			if k > 0 && k < fftSize/2 {
+				// Keep the spectrum conjugate-symmetric so the output is real
+				buf[fftSize-k] = cmplx.Conj(buf[k])
+			}
+		}
+		fft(buf, true)
+		for i := range buf {
+			out[f*hopSize+i] += real(buf[i]) * window[i]
+		}
+	}
+	// The first and last half frames are covered by only one window, so
+	// they pass through unchanged along with any samples past the end
+	copy(out[:hopSize], samples[:hopSize])
+	copy(out[frames*hopSize:], samples[frames*hopSize:])
+	return out
+}
+
+// analyse fills buf with the spectrum of the frame starting at samples.
+func analyse(buf []complex128, samples, window []float64) {
+	for i := range buf {
+		buf[i] = complex(samples[i]*window[i], 0)
+	}
+	fft(buf, false)
+}
+
+func power(c complex128) float64 {
+	return real(c)*real(c) + imag(c)*imag(c)
+}
+
+// fft computes an in-place radix-2 FFT of x, whose length must be a power of
+// two. The inverse transform is scaled by 1/len(x).
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
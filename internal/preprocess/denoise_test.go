@@ -0,0 +1,49 @@
+package preprocess
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func rms(samples []float64) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestDenoise(t *testing.T) {
+	// Five seconds of hiss with a tone in the middle three
+	r := rand.New(rand.NewSource(1))
+	samples := make([]float64, 5*16000)
+	tone := make([]float64, len(samples))
+	for i := range samples {
+		if i >= 16000 && i < 4*16000 {
+			tone[i] = 0.3 * math.Sin(2*math.Pi*440*float64(i)/16000)
+		}
+		samples[i] = tone[i] + 0.01*r.NormFloat64()
+	}
+	hiss := rms(samples[:16000])
+
+	out := Denoise{Strength: 1.5, Floor: 0.1}.Apply(append([]float64(nil), samples...))
+	if len(out) != len(samples) {
+		t.Fatalf("got %d samples, want %d", len(out), len(samples))
+	}
+	if got := rms(out[hopSize:16000]); got > hiss*0.6 {
+		t.Errorf("hiss went from %.4f to %.4f RMS", hiss, got)
+	}
+	speech := out[2*16000 : 3*16000]
+	if got, want := rms(speech), rms(tone[2*16000:3*16000]); math.Abs(got-want) > want*0.05 {
+		t.Errorf("tone RMS %.4f, want %.4f", got, want)
+	}
+}
+
+func TestDenoiseShort(t *testing.T) {
+	samples := []float64{0.1, -0.2, 0.3}
+	out := Denoise{Strength: 1, Floor: 0.1}.Apply(samples)
+	if len(out) != 3 || out[0] != 0.1 || out[2] != 0.3 {
+		t.Errorf("short input changed: %v", out)
+	}
+}
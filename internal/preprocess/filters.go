@@ -0,0 +1,108 @@
+package preprocess
+
+import (
+	"jtt/internal/audio"
+	"math"
+	"time"
+)
+
+// HighPass removes low-frequency rumble (desk thumps, HVAC, handling noise)
+// below CutoffHz with a second-order Butterworth filter.
+type HighPass struct {
+	CutoffHz float64
+}
+
+func (HighPass) Name() string { return "high-pass" }
+
+func (h HighPass) Apply(samples []float64) []float64 {
+	// RBJ audio EQ cookbook high-pass biquad, Q = 1/sqrt(2)
+	w0 := 2 * math.Pi * h.CutoffHz / audio.SampleRate
+	cos, alpha := math.Cos(w0), math.Sin(w0)/math.Sqrt2
+	a0 := 1 + alpha
+	b0 := (1 + cos) / 2 / a0
+	b1 := -(1 + cos) / a0
+	b2 := b0
+	a1 := -2 * cos / a0
+	a2 := (1 - alpha) / a0
+
+	var x1, x2, y1, y2 float64
+	for i, x := range samples {
+		y := b0*x + b1*x1 + b2*x2 - a1*y1 - a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		samples[i] = y
+	}
+	return samples
+}
+
+// TrimSilence cuts leading and trailing audio quieter than ThresholdDB,
+// keeping Padding around the speech so word edges aren't clipped.
+// Recordings with no detectable speech are left alone.
+type TrimSilence struct {
+	ThresholdDB float64
+	Padding     time.Duration
+}
+
+func (TrimSilence) Name() string { return "trim silence" }
+
+func (t TrimSilence) Apply(samples []float64) []float64 {
+	first, last := -1, -1
+	for i := 0; i+frameSize <= len(samples); i += frameSize {
+		if frameDBFS(samples[i:i+frameSize]) > t.ThresholdDB {
+			if first < 0 {
+				first = i
+			}
+			last = i + frameSize
+		}
+	}
+	if first < 0 {
+		return samples
+	}
+
+	pad := int(t.Padding.Seconds() * audio.SampleRate)
+	return samples[max(first-pad, 0):min(last+pad, len(samples))]
+}
+
+// Normalize scales the recording so speech averages TargetDB, measured over
+// frames louder than GateDB so pauses don't drag the level down. The gain is
+// capped at MaxGainDB and limited to keep peaks below -1 dBFS.
+type Normalize struct {
+	TargetDB  float64
+	GateDB    float64
+	MaxGainDB float64
+}
+
+func (Normalize) Name() string { return "normalize" }
+
+func (n Normalize) Apply(samples []float64) []float64 {
+	var sum float64
+	var count int
+	peak := 0.0
+	for i := 0; i+frameSize <= len(samples); i += frameSize {
+		frame := samples[i : i+frameSize]
+		if frameDBFS(frame) <= n.GateDB {
+			continue
+		}
+		for _, s := range frame {
+			sum += s * s
+		}
+		count += len(frame)
+	}
+	for _, s := range samples {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	if count == 0 || peak == 0 {
+		return samples
+	}
+
+	level := audio.DBFS(math.Sqrt(sum / float64(count)))
+	gainDB := math.Min(n.TargetDB-level, n.MaxGainDB)
+	// Don't let the loudest sample clip
+	gainDB = math.Min(gainDB, -1-audio.DBFS(peak))
+
+	gain := math.Pow(10, gainDB/20)
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return samples
+}
@@ -0,0 +1,65 @@
+// Package preprocess cleans up recorded audio before transcription: it
+// removes rumble and steady background noise, trims silence and evens out
+// the loudness, all in Go on the PCM samples.
+package preprocess
+
+import (
+	"jtt/internal/audio"
+	"jtt/internal/logger"
+	"math"
+	"time"
+)
+
+// Step transforms a recording's samples, scaled to -1..1 at
+// audio.SampleRate.
+type Step interface {
+	Name() string
+	Apply(samples []float64) []float64
+}
+
+// Chain runs steps in order over a WAV file. It implements
+// pipeline.AudioFilter.
+type Chain struct {
+	Steps []Step
+}
+
+// Filter rewrites the WAV file at path with every step applied.
+func (c Chain) Filter(path string) error {
+	if len(c.Steps) == 0 {
+		return nil
+	}
+
+	samples, err := audio.ReadSamples(path)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	before := len(samples)
+	for _, step := range c.Steps {
+		samples = step.Apply(samples)
+	}
+	logger.Info("preprocess: %.2fs -> %.2fs of audio in %s",
+		seconds(before), seconds(len(samples)), time.Since(start).Round(time.Millisecond))
+
+	return audio.WriteSamples(path, samples)
+}
+
+func seconds(n int) float64 {
+	return float64(n) / audio.SampleRate
+}
+
+// frameSize is the analysis window for the level-based steps.
+var frameSize = int(audio.FrameDuration.Seconds() * audio.SampleRate)
+
+// frameDBFS returns the RMS level of samples in dBFS.
+func frameDBFS(samples []float64) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	return audio.DBFS(math.Sqrt(sum / float64(len(samples))))
+}
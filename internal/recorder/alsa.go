@@ -0,0 +1,83 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// alsaBackend records with arecord. Device IDs are ALSA PCM names as listed
+// by `arecord -L`, e.g. "default" or "plughw:CARD=PCH,DEV=0".
+type alsaBackend struct{}
+
+func (alsaBackend) Name() string { return "alsa" }
+
+func (alsaBackend) Available() bool {
+	_, err := exec.LookPath("arecord")
+	return err == nil
+}
+
+func (alsaBackend) ListMicrophones() ([]Microphone, error) {
+	output, err := exec.Command("arecord", "-L").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseALSADevices(output), nil
+}
+
+func (alsaBackend) Open(device string) (Stream, error) {
+	args := []string{"-q", "-t", "raw", "-f", "S16_LE", "-r", "16000", "-c", "1"}
+	if device != "" {
+		args = append(args, "-D", device)
+	}
+	return startProcess(exec.Command("arecord", args...))
+}
+
+// parseALSADevices parses `arecord -L`: PCM names start at column 0 and are
+// followed by indented description lines. Only the plughw devices (which
+// resample to our format) and named plugins are kept; null, raw hw and
+// surround outputs are skipped.
+func parseALSADevices(output []byte) []Microphone {
+	mics := []Microphone{defaultMicrophone}
+
+	var name string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			name = strings.TrimSpace(line)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		// First description line names the device
+		if skipALSADevice(name) {
+			name = ""
+			continue
+		}
+		desc := strings.TrimSpace(line)
+		if strings.HasPrefix(name, "plughw:") {
+			desc += " (" + name + ")"
+		}
+		mics = append(mics, Microphone{ID: name, Name: desc})
+		name = ""
+	}
+	return mics
+}
+
+func skipALSADevice(name string) bool {
+	if name == "null" || name == "default" {
+		return true
+	}
+	prefix, _, _ := strings.Cut(name, ":")
+	switch prefix {
+	case "plughw", "pulse", "pipewire", "sysdefault":
+		return false
+	}
+	return strings.Contains(name, ":")
+}
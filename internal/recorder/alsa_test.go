@@ -0,0 +1,68 @@
+package recorder
+
+import "testing"
+
+// arecordL is `arecord -L` from a laptop with PipeWire and a USB headset.
+const arecordL = `null
+    Discard all samples (playback) or generate zero samples (capture)
+pipewire
+    PipeWire Sound Server
+default
+    Default ALSA Output (currently PipeWire Media Server)
+sysdefault:CARD=PCH
+    HDA Intel PCH, ALC257 Analog
+    Default Audio Device
+front:CARD=PCH,DEV=0
+    HDA Intel PCH, ALC257 Analog
+    Front output / input
+dmix:CARD=PCH,DEV=0
+    HDA Intel PCH, ALC257 Analog
+    Direct sample mixing device
+hw:CARD=PCH,DEV=0
+    HDA Intel PCH, ALC257 Analog
+    Direct hardware device without any conversions
+plughw:CARD=PCH,DEV=0
+    HDA Intel PCH, ALC257 Analog
+    Hardware device with all software conversions
+usbstream:CARD=PCH
+    HDA Intel PCH
+    USB Stream Output
+sysdefault:CARD=Headset
+	Jabra EVOLVE 20, USB Audio
+	Default Audio Device
+plughw:CARD=Headset,DEV=0
+	Jabra EVOLVE 20, USB Audio
+	Hardware device with all software conversions
+`
+
+func TestParseALSADevices(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Microphone
+	}{
+		{"empty", "", []Microphone{defaultMicrophone}},
+		{"laptop", arecordL, []Microphone{
+			defaultMicrophone,
+			{ID: "pipewire", Name: "PipeWire Sound Server"},
+			{ID: "sysdefault:CARD=PCH", Name: "HDA Intel PCH, ALC257 Analog"},
+			{ID: "plughw:CARD=PCH,DEV=0", Name: "HDA Intel PCH, ALC257 Analog (plughw:CARD=PCH,DEV=0)"},
+			{ID: "sysdefault:CARD=Headset", Name: "Jabra EVOLVE 20, USB Audio"},
+			{ID: "plughw:CARD=Headset,DEV=0", Name: "Jabra EVOLVE 20, USB Audio (plughw:CARD=Headset,DEV=0)"},
+		}},
+		// A name without a description isn't a device
+		{"no description", "pulse\n\nnull\n", []Microphone{defaultMicrophone}},
+	}
+	for _, tt := range tests {
+		got := parseALSADevices([]byte(tt.output))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: device %d is %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
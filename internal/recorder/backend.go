@@ -0,0 +1,79 @@
+package recorder
+
+import (
+	"fmt"
+	"io"
+)
+
+// Backend captures 16 kHz mono 16-bit PCM from a microphone.
+type Backend interface {
+	Name() string
+	// Available reports whether the backend can be used on this machine.
+	Available() bool
+	ListMicrophones() ([]Microphone, error)
+	// Open starts capturing from the device with the given ID, or the
+	// system default if device is empty.
+	Open(device string) (Stream, error)
+}
+
+// Stream is an open capture. Close stops capturing; Read keeps returning any
+// buffered samples and then io.EOF.
+type Stream interface {
+	io.Reader
+	Close() error
+}
+
+// backends lists every capture backend in order of preference for "auto".
+var backends = []Backend{
+	nativeBackend{},
+	pipewireBackend{},
+	pulseBackend{},
+	alsaBackend{},
+	soxBackend{},
+}
+
+// candidates returns the backends to try for the configured name: just the
+// named one, or every available backend in preference order for "auto".
+func candidates(name string) ([]Backend, error) {
+	if name != "" && name != "auto" {
+		for _, b := range backends {
+			if b.Name() == name {
+				return []Backend{b}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown audio backend: %s", name)
+	}
+
+	var available []Backend
+	for _, b := range backends {
+		if b.Available() {
+			available = append(available, b)
+		}
+	}
+	if len(available) == 0 {
+		return nil, fmt.Errorf("no audio backend available")
+	}
+	return available, nil
+}
+
+// isBackend reports whether name is a known backend.
+func isBackend(name string) bool {
+	for _, b := range backends {
+		if b.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableBackends returns "auto" plus the names of the backends usable on
+// this machine, for Config.AudioBackend.
+func AvailableBackends() []string {
+	names := []string{"auto"}
+	for _, b := range backends {
+		if b.Available() {
+			names = append(names, b.Name())
+		}
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package recorder
+
+import (
+	"jtt/internal/audio"
+	"time"
+)
+
+// Chunk is a piece of a recording cut at a pause, handed to OnChunk while
+// recording goes on. Chunks without speech are not handed out.
+type Chunk struct {
+	Index int
+	Start time.Duration // offset into the recording
+	PCM   []byte
+}
+
+// Chunk lengths. A chunk is cut at the first pause once it is long enough
+// to give whisper some context; past chunkSoftMax a shorter pause will do,
+// so a talker who never stops still gets chunks of a bounded size.
+const (
+	chunkMin     = 5 * time.Second
+	chunkSoftMax = 25 * time.Second
+	chunkPause   = 600 * time.Millisecond
+	chunkPauseLo = 200 * time.Millisecond
+)
+
+// chunker splits captured audio into chunks at pauses in speech.
+type chunker struct {
+	threshold float64
+	fn        func(Chunk)
+
+	vad   *audio.VAD
+	buf   []byte
+	start int64 // bytes of the recording before buf
+	index int
+}
+
+func newChunker(thresholdDB float64, fn func(Chunk)) *chunker {
+	return &chunker{threshold: thresholdDB, fn: fn, vad: audio.NewVAD(thresholdDB)}
+}
+
+func (c *chunker) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	c.vad.Write(p)
+
+	pause := chunkPause
+	if int64(len(c.buf)) >= bytesFor(chunkSoftMax) {
+		pause = chunkPauseLo
+	}
+	if int64(len(c.buf)) < bytesFor(chunkMin) || !c.vad.HeardSpeech() || c.vad.Silence() < pause {
+		return len(p), nil
+	}
+
+	// Cut in the middle of the pause so neither side clips a word, keeping
+	// the cut on a sample boundary
+	cut := len(c.buf) - int(bytesFor(c.vad.Silence()/2))
+	cut -= cut % (audio.BitsPerSample / 8)
+	c.emit(c.buf[:cut], true)
+
+	rest := append([]byte(nil), c.buf[cut:]...)
+	c.start += int64(cut)
+	c.buf = rest
+	c.vad = audio.NewVAD(c.threshold)
+	c.vad.Write(rest)
+	return len(p), nil
+}
+
+// flush hands out whatever is left once the recording stops.
+func (c *chunker) flush() {
+	c.emit(c.buf, c.vad.HeardSpeech())
+	c.buf = nil
+}
+
+func (c *chunker) emit(pcm []byte, speech bool) {
+	if !speech || len(pcm) == 0 {
+		return
+	}
+	c.fn(Chunk{
+		Index: c.index,
+		Start: time.Duration(float64(c.start) / float64(bytesFor(time.Second)) * float64(time.Second)),
+		PCM:   append([]byte(nil), pcm...),
+	})
+	c.index++
+}
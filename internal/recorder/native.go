@@ -0,0 +1,169 @@
+//go:build cgo
+
+package recorder
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"jtt/internal/audio"
+	"sync"
+	"unsafe"
+
+	"github.com/gen2brain/malgo"
+)
+
+// nativeBackend captures in-process through miniaudio, which talks to
+// CoreAudio, PulseAudio/ALSA or WASAPI directly. Device IDs are the hex form
+// of miniaudio's device ID, which wraps the OS's persistent device UID
+// (CoreAudio UID, PulseAudio source name, WASAPI endpoint ID).
+type nativeBackend struct{}
+
+func (nativeBackend) Name() string { return "native" }
+
+func (nativeBackend) Available() bool {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return false
+	}
+	ctx.Uninit()
+	ctx.Free()
+	return true
+}
+
+func (nativeBackend) ListMicrophones() ([]Microphone, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		ctx.Uninit()
+		ctx.Free()
+	}()
+
+	infos, err := ctx.Devices(malgo.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	mics := []Microphone{defaultMicrophone}
+	for _, info := range infos {
+		mics = append(mics, Microphone{ID: info.ID.String(), Name: info.Name()})
+	}
+	return mics, nil
+}
+
+func (nativeBackend) Open(device string) (Stream, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := malgo.DefaultDeviceConfig(malgo.Capture)
+	cfg.Capture.Format = malgo.FormatS16
+	cfg.Capture.Channels = audio.Channels
+	cfg.SampleRate = audio.SampleRate
+
+	var deviceID unsafe.Pointer
+	if device != "" {
+		id, err := findDevice(ctx, device)
+		if err != nil {
+			ctx.Uninit()
+			ctx.Free()
+			return nil, err
+		}
+		// Pointer copies the ID to C memory, which miniaudio may read until
+		// the device is uninitialised; the stream frees it then
+		deviceID = id.Pointer()
+		cfg.Capture.DeviceID = deviceID
+	}
+
+	s := &nativeStream{ctx: ctx, deviceID: deviceID}
+	s.cond = sync.NewCond(&s.mu)
+
+	dev, err := malgo.InitDevice(ctx.Context, cfg, malgo.DeviceCallbacks{
+		Data: func(_, input []byte, _ uint32) {
+			s.push(input)
+		},
+	})
+	if err != nil {
+		ctx.Uninit()
+		ctx.Free()
+		C.free(deviceID)
+		return nil, err
+	}
+	s.dev = dev
+
+	if err := dev.Start(); err != nil {
+		dev.Uninit()
+		ctx.Uninit()
+		ctx.Free()
+		C.free(deviceID)
+		return nil, err
+	}
+	return s, nil
+}
+
+func findDevice(ctx *malgo.AllocatedContext, id string) (malgo.DeviceID, error) {
+	infos, err := ctx.Devices(malgo.Capture)
+	if err != nil {
+		return malgo.DeviceID{}, err
+	}
+	for _, info := range infos {
+		if info.ID.String() == id {
+			return info.ID, nil
+		}
+	}
+	return malgo.DeviceID{}, fmt.Errorf("microphone not found: %s", id)
+}
+
+// nativeStream buffers samples delivered by miniaudio's capture callback
+// until Read consumes them.
+type nativeStream struct {
+	ctx      *malgo.AllocatedContext
+	dev      *malgo.Device
+	deviceID unsafe.Pointer // C copy of the device ID; nil for the default
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	closed bool
+}
+
+// push is called on miniaudio's audio thread; it must not block for long.
+func (s *nativeStream) push(samples []byte) {
+	s.mu.Lock()
+	s.buf.Write(samples)
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+func (s *nativeStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.buf.Len() == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if s.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return s.buf.Read(p)
+}
+
+func (s *nativeStream) Close() error {
+	// Uninit stops the device and waits for the last callback to return
+	s.dev.Uninit()
+	s.ctx.Uninit()
+	s.ctx.Free()
+	C.free(s.deviceID)
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !cgo
+
+package recorder
+
+import "errors"
+
+// nativeBackend needs cgo for miniaudio; without it only the external
+// recorder backends are available.
+type nativeBackend struct{}
+
+var errNoCgo = errors.New("native audio capture requires cgo")
+
+func (nativeBackend) Name() string { return "native" }
+
+func (nativeBackend) Available() bool { return false }
+
+func (nativeBackend) ListMicrophones() ([]Microphone, error) { return nil, errNoCgo }
+
+func (nativeBackend) Open(device string) (Stream, error) { return nil, errNoCgo }
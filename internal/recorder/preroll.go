@@ -0,0 +1,146 @@
+package recorder
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// preRoll keeps the mic open between recordings and buffers the most recent
+// audio, so a recording can begin with the moment before the hotkey was
+// pressed instead of clipping the first word while a device opens.
+type preRoll struct {
+	stream Stream
+	device DeviceEvent
+	key    string // backend and microphone the stream was opened with
+	done   chan struct{}
+	opened time.Time
+	lost   func(*preRoll) // called if the stream ends without being closed
+
+	mu      sync.Mutex
+	closing bool
+	ring    []byte
+	pos     int
+	full    bool
+	odd     bool      // the stream has delivered an odd number of bytes
+	skip    bool      // the ring started filling mid-sample
+	dst     io.Writer // set while a recording captures from this stream
+}
+
+func newPreRoll(stream Stream, device DeviceEvent, key string, size int, lost func(*preRoll)) *preRoll {
+	p := &preRoll{
+		stream: stream,
+		device: device,
+		key:    key,
+		done:   make(chan struct{}),
+		opened: time.Now(),
+		lost:   lost,
+		ring:   make([]byte, size),
+	}
+	go func() {
+		io.Copy(p, stream)
+		close(p.done)
+		p.mu.Lock()
+		closing := p.closing
+		p.mu.Unlock()
+		if !closing && p.lost != nil {
+			p.lost(p)
+		}
+	}()
+	return p
+}
+
+// Write hands captured audio to the active recording, or keeps it in the
+// ring buffer while idle.
+func (p *preRoll) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(b)
+	p.odd = p.odd != (n%2 == 1)
+
+	if p.dst != nil {
+		// A failing recording must not stop the pre-roll stream
+		p.dst.Write(b)
+		return n, nil
+	}
+
+	if n >= len(p.ring) {
+		copy(p.ring, b[n-len(p.ring):])
+		p.pos, p.full = 0, true
+		return n, nil
+	}
+	c := copy(p.ring[p.pos:], b)
+	if c < n {
+		copy(p.ring, b[c:])
+		p.full = true
+	}
+	p.pos = (p.pos + n) % len(p.ring)
+	if p.pos == 0 {
+		p.full = true
+	}
+	return n, nil
+}
+
+// attach starts a recording into dst, beginning with the buffered audio.
+func (p *preRoll) attach(dst io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	buffered := p.ring[:p.pos]
+	skip := p.skip
+	if p.full {
+		buffered = append(append([]byte{}, p.ring[p.pos:]...), p.ring[:p.pos]...)
+		// The ring has an even size, so once full it starts mid-sample
+		// whenever the stream has delivered an odd number of bytes
+		skip = p.odd
+	}
+	if skip && len(buffered) > 0 {
+		buffered = buffered[1:]
+	}
+	if _, err := dst.Write(buffered); err != nil {
+		return err
+	}
+	p.pos, p.full = 0, false
+	p.dst = dst
+	return nil
+}
+
+// resume continues a recording from another stream that ended, without the
+// buffered audio, which was recorded before the recording started.
+func (p *preRoll) resume(dst io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pos, p.full = 0, false
+	p.dst = dst
+}
+
+// detach ends the recording and returns where it was writing; once it
+// returns nothing more is written there.
+func (p *preRoll) detach() io.Writer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	dst := p.dst
+	p.dst = nil
+	p.skip = p.odd
+	return dst
+}
+
+// alive reports whether the stream is still delivering audio.
+func (p *preRoll) alive() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *preRoll) close() error {
+	p.mu.Lock()
+	p.closing = true
+	p.mu.Unlock()
+	err := p.stream.Close()
+	<-p.done
+	return err
+}
@@ -0,0 +1,192 @@
+package recorder
+
+import (
+	"io"
+	"jtt/internal/audio"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStream delivers audio handed to send. send returns once the audio has
+// been read and written on, so tests don't race the capture goroutine.
+type fakeStream struct {
+	data    chan []byte
+	waiting chan struct{} // holds a token while Read waits for data
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{
+		data:    make(chan []byte),
+		waiting: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *fakeStream) Read(b []byte) (int, error) {
+	select {
+	case s.waiting <- struct{}{}:
+	case <-s.closed:
+		return 0, io.EOF
+	}
+	select {
+	case chunk, ok := <-s.data:
+		if !ok {
+			return 0, io.EOF
+		}
+		return copy(b, chunk), nil
+	case <-s.closed:
+		return 0, io.EOF
+	}
+}
+
+func (s *fakeStream) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return nil
+}
+
+func (s *fakeStream) send(b []byte) {
+	<-s.waiting
+	s.data <- b
+	// The next Read means b has been handled
+	s.waiting <- <-s.waiting
+}
+
+// end makes the stream stop on its own, as a recorder process that exits does.
+func (s *fakeStream) end() {
+	<-s.waiting
+	close(s.data)
+}
+
+// fakeBackend hands out the streams it opens on opened.
+type fakeBackend struct {
+	opened chan *fakeStream
+}
+
+func (fakeBackend) Name() string                           { return "fake" }
+func (fakeBackend) Available() bool                        { return true }
+func (fakeBackend) ListMicrophones() ([]Microphone, error) { return nil, nil }
+
+func (b fakeBackend) Open(device string) (Stream, error) {
+	s := newFakeStream()
+	b.opened <- s
+	return s, nil
+}
+
+func newFakeRecorder(t *testing.T) (*Recorder, chan *fakeStream) {
+	t.Helper()
+	opened := make(chan *fakeStream, 4)
+	saved := backends
+	backends = []Backend{fakeBackend{opened: opened}}
+	t.Cleanup(func() { backends = saved })
+
+	dir := t.TempDir()
+	r := New("fake", "")
+	r.audioPath = filepath.Join(dir, "recording.wav")
+	r.pidPath = filepath.Join(dir, "rec.pid")
+	t.Cleanup(func() { r.Close() })
+	return r, opened
+}
+
+func nextStream(t *testing.T, opened chan *fakeStream) *fakeStream {
+	t.Helper()
+	select {
+	case s := <-opened:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stream opened")
+		return nil
+	}
+}
+
+func TestPreRoll(t *testing.T) {
+	r, opened := newFakeRecorder(t)
+	if err := r.SetPreRoll(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	stream := nextStream(t, opened)
+	if !r.PreRollActive() {
+		t.Fatal("pre-roll not active")
+	}
+
+	// Only the last 100ms before the recording is kept
+	stream.send(make([]byte, bytesFor(time.Second)))
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stream.send(make([]byte, bytesFor(200*time.Millisecond)))
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	// Audio after the recording stopped goes back to the ring
+	stream.send(make([]byte, bytesFor(50*time.Millisecond)))
+
+	if got, err := audio.Duration(r.AudioPath()); err != nil || got != 0.3 {
+		t.Errorf("recorded %.3fs, want 0.3s: %v", got, err)
+	}
+	if !r.PreRollActive() {
+		t.Error("pre-roll closed after the recording")
+	}
+}
+
+// TestPreRollReopen checks that a pre-roll stream that ends on its own, as
+// sox used to after 10 minutes, is reopened without losing the recording.
+func TestPreRollReopen(t *testing.T) {
+	saved := reopenAfter
+	reopenAfter = 0
+	t.Cleanup(func() { reopenAfter = saved })
+	r, opened := newFakeRecorder(t)
+	if err := r.SetPreRoll(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	first := nextStream(t, opened)
+	first.send(make([]byte, bytesFor(100*time.Millisecond)))
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	first.send(make([]byte, bytesFor(100*time.Millisecond)))
+
+	first.end()
+	second := nextStream(t, opened)
+	second.send(make([]byte, bytesFor(100*time.Millisecond)))
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := audio.Duration(r.AudioPath()); err != nil || got != 0.3 {
+		t.Errorf("recorded %.3fs, want 0.3s: %v", got, err)
+	}
+	if !r.PreRollActive() {
+		t.Error("pre-roll not active after reopening")
+	}
+}
+
+func TestPreRollGivesUp(t *testing.T) {
+	r, opened := newFakeRecorder(t)
+	events := make(chan DeviceEvent, 4)
+	r.OnDeviceChange(func(e DeviceEvent) { events <- e })
+	if err := r.SetPreRoll(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	nextStream(t, opened).end()
+
+	select {
+	case e := <-events:
+		if e.Warning == "" {
+			t.Errorf("no warning in %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the pre-roll was not reported")
+	}
+	if r.PreRollActive() {
+		t.Error("pre-roll still active")
+	}
+	select {
+	case <-opened:
+		t.Error("a stream that died straight away was reopened")
+	default:
+	}
+}
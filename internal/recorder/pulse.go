@@ -0,0 +1,87 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// pulseBackend records with parecord from PulseAudio (or pipewire-pulse).
+// Device IDs are PulseAudio source names.
+type pulseBackend struct{}
+
+func (pulseBackend) Name() string { return "pulse" }
+
+func (pulseBackend) Available() bool {
+	_, err := exec.LookPath("parecord")
+	return err == nil
+}
+
+func (pulseBackend) ListMicrophones() ([]Microphone, error) {
+	return listPulseSources()
+}
+
+func (pulseBackend) Open(device string) (Stream, error) {
+	args := []string{"--raw", "--format=s16le", "--rate=16000", "--channels=1"}
+	if device != "" {
+		args = append(args, "--device="+device)
+	}
+	return startProcess(exec.Command("parecord", args...))
+}
+
+// pipewireBackend records with pw-record. PipeWire nodes are addressed by the
+// same names pactl reports through pipewire-pulse.
+type pipewireBackend struct{}
+
+func (pipewireBackend) Name() string { return "pipewire" }
+
+func (pipewireBackend) Available() bool {
+	_, err := exec.LookPath("pw-record")
+	return err == nil
+}
+
+func (pipewireBackend) ListMicrophones() ([]Microphone, error) {
+	return listPulseSources()
+}
+
+func (pipewireBackend) Open(device string) (Stream, error) {
+	args := []string{"--format", "s16", "--rate", "16000", "--channels", "1"}
+	if device != "" {
+		args = append(args, "--target", device)
+	}
+	return startProcess(exec.Command("pw-record", append(args, "-")...))
+}
+
+// listPulseSources parses `pactl list sources`, skipping the monitor sources
+// that capture playback rather than a microphone.
+func listPulseSources() ([]Microphone, error) {
+	output, err := exec.Command("pactl", "list", "sources").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePulseSources(output), nil
+}
+
+func parsePulseSources(output []byte) []Microphone {
+	mics := []Microphone{defaultMicrophone}
+
+	var name string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Source #"):
+			name = ""
+		case strings.HasPrefix(line, "Name:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
+		case strings.HasPrefix(line, "Description:") && name != "":
+			if !strings.HasSuffix(name, ".monitor") {
+				desc := strings.TrimSpace(strings.TrimPrefix(line, "Description:"))
+				mics = append(mics, Microphone{ID: name, Name: desc})
+			}
+			name = ""
+		}
+	}
+	return mics
+}
@@ -0,0 +1,64 @@
+package recorder
+
+import "testing"
+
+// pactlSources is `pactl list sources` from PipeWire's PulseAudio server,
+// trimmed of most properties.
+const pactlSources = `Source #54
+	State: SUSPENDED
+	Name: alsa_output.pci-0000_00_1f.3.analog-stereo.monitor
+	Description: Monitor of Built-in Audio Analog Stereo
+	Driver: PipeWire
+	Sample Specification: s32le 2ch 48000Hz
+	Properties:
+		device.description = "Built-in Audio Analog Stereo"
+		device.class = "monitor"
+
+Source #55
+	State: RUNNING
+	Name: alsa_input.pci-0000_00_1f.3.analog-stereo
+	Description: Built-in Audio Analog Stereo
+	Driver: PipeWire
+	Sample Specification: s32le 2ch 48000Hz
+	Properties:
+		device.description = "Built-in Audio Analog Stereo"
+	Ports:
+		analog-input-mic: Microphone (type: Mic, priority: 8700, availability unknown)
+	Active Port: analog-input-mic
+
+Source #71
+	State: SUSPENDED
+	Name: alsa_input.usb-GN_Netcom_A_S_Jabra_EVOLVE_20-00.mono-fallback
+	Description: Jabra EVOLVE 20 Mono
+	Driver: PipeWire
+	Sample Specification: s16le 1ch 16000Hz
+`
+
+func TestParsePulseSources(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Microphone
+	}{
+		{"empty", "", []Microphone{defaultMicrophone}},
+		{"sources", pactlSources, []Microphone{
+			defaultMicrophone,
+			{ID: "alsa_input.pci-0000_00_1f.3.analog-stereo", Name: "Built-in Audio Analog Stereo"},
+			{ID: "alsa_input.usb-GN_Netcom_A_S_Jabra_EVOLVE_20-00.mono-fallback", Name: "Jabra EVOLVE 20 Mono"},
+		}},
+		// A description only counts for the source it follows
+		{"description without a name", "Source #1\n\tDescription: Orphan\n", []Microphone{defaultMicrophone}},
+	}
+	for _, tt := range tests {
+		got := parsePulseSources([]byte(tt.output))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: source %d is %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -1,135 +1,353 @@
 package recorder
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"jtt/internal/audio"
+	"jtt/internal/logger"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 )
 
 type Recorder struct {
-	cmd        *exec.Cmd
-	audioPath  string
-	pidPath    string
-	microphone string
+	mu             sync.Mutex
+	audioPath      string
+	pidPath        string
+	backend        string
+	microphone     string
+	onDeviceChange func(DeviceEvent)
+
+	// Auto-stop: onSilence fires once speech is followed by silenceAfter of
+	// audio quieter than silenceThreshold dBFS. Zero silenceAfter disables it.
+	silenceThreshold float64
+	silenceAfter     time.Duration
+	onSilence        func()
+
+	onLevel   func(Level)
+	onNoInput func()
+
+	// Chunking: while on, audio is cut at pauses quieter than
+	// chunkThreshold dBFS and handed to onChunk during the recording
+	chunking       bool
+	chunkThreshold float64
+	onChunk        func(Chunk)
+	chunker        *chunker // the current recording's, if chunking
+
+	preRollDuration time.Duration
+	warm            *preRoll // open between recordings while pre-roll is on
+
+	stream Stream
+	writer *audio.Writer
+	done   chan error
+	active Microphone
+	names  map[string]string // display names from the last ListMicrophones
 }
 
+// Microphone is an input device. IDs are "<backend>:<device>", e.g.
+// "pulse:alsa_input.usb-Blue_Yeti-00.analog-stereo", so they stay stable
+// across renames and reboots; the empty ID is the system default.
 type Microphone struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
-// ListMicrophones returns available audio input devices on macOS
-func ListMicrophones() ([]Microphone, error) {
-	return listMicrophonesFromSystemProfiler()
+var defaultMicrophone = Microphone{ID: "", Name: "System Default"}
+
+// Level is a periodic report of the input level during a recording.
+type Level struct {
+	RMS     float64 `json:"rms"`     // 0..1
+	Peak    float64 `json:"peak"`    // 0..1
+	Elapsed float64 `json:"elapsed"` // seconds of audio captured
 }
 
-func listMicrophonesFromSystemProfiler() ([]Microphone, error) {
-	cmd := exec.Command("system_profiler", "SPAudioDataType")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+const (
+	levelInterval = 100 * time.Millisecond
+	// noInputAfter is how long the input may stay at digital silence, as a
+	// muted or disconnected mic produces, before OnNoInput fires
+	noInputAfter = 3 * time.Second
+)
+
+// reopenAfter is how long a pre-roll stream must have run before it is
+// reopened when it ends. Tests shorten it.
+var reopenAfter = time.Second
+
+// DeviceEvent reports the device a recording actually uses. Warning is set
+// when the configured microphone could not be used and the system default
+// was picked instead.
+type DeviceEvent struct {
+	Device    Microphone `json:"device"`
+	Requested string     `json:"requested"`
+	Warning   string     `json:"warning,omitempty"`
+}
+
+// New returns a recorder using the named backend ("auto" or "" picks the
+// best available one) and microphone ID ("" for the system default).
+func New(backend, microphone string) *Recorder {
+	homeDir, _ := os.UserHomeDir()
+	cacheDir := filepath.Join(homeDir, ".cache", "jtt")
+	return &Recorder{
+		audioPath:  filepath.Join(cacheDir, "recording.wav"),
+		pidPath:    filepath.Join(cacheDir, "rec.pid"),
+		backend:    backend,
+		microphone: microphone,
 	}
+}
 
-	var mics []Microphone
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-
-	var currentDevice string
-	var hasInput bool
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Detect device names: lines with 8 spaces indent ending with ":"
-		// (not property lines which have 10+ spaces)
-		if strings.HasPrefix(line, "        ") && !strings.HasPrefix(line, "          ") &&
-			strings.HasSuffix(trimmed, ":") &&
-			!strings.Contains(trimmed, "Devices:") {
-			// Save previous device if it had input
-			if hasInput && currentDevice != "" {
-				found := false
-				for _, m := range mics {
-					if m.Name == currentDevice {
-						found = true
-						break
-					}
-				}
-				if !found {
-					mics = append(mics, Microphone{
-						ID:   currentDevice,
-						Name: currentDevice,
-					})
-				}
-			}
-			currentDevice = strings.TrimSuffix(trimmed, ":")
-			hasInput = false
-		}
+func (r *Recorder) SetMicrophone(mic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.microphone = mic
+	r.reopenPreRoll()
+}
 
-		// Check if device has input capabilities
-		if strings.Contains(trimmed, "Input Channels:") || strings.Contains(trimmed, "Input Source:") {
-			hasInput = true
-		}
+// SetBackend changes the capture backend used by the next recording.
+func (r *Recorder) SetBackend(backend string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backend = backend
+	r.reopenPreRoll()
+}
+
+// SetPreRoll keeps the mic open between recordings and starts each recording
+// with the last d of audio, so the first word isn't lost while the device
+// opens. Zero closes the mic between recordings.
+func (r *Recorder) SetPreRoll(d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preRollDuration = d
+	if r.capturingFromPreRoll() {
+		// Applied when the recording stops
+		return nil
 	}
+	return r.syncPreRoll()
+}
 
-	// Don't forget the last device
-	if hasInput && currentDevice != "" {
-		found := false
-		for _, m := range mics {
-			if m.Name == currentDevice {
-				found = true
-				break
-			}
-		}
-		if !found {
-			mics = append(mics, Microphone{
-				ID:   currentDevice,
-				Name: currentDevice,
-			})
-		}
+// PreRollActive reports whether the mic is being held open for pre-roll.
+func (r *Recorder) PreRollActive() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.warm != nil && r.warm.alive()
+}
+
+// reopenPreRoll applies a device change to an open pre-roll stream.
+func (r *Recorder) reopenPreRoll() {
+	if r.warm == nil || r.capturingFromPreRoll() {
+		return
 	}
+	if err := r.syncPreRoll(); err != nil {
+		logger.Error("recorder: failed to reopen pre-roll stream: %v", err)
+	}
+}
 
-	// Always include "default" option
-	defaultMic := Microphone{ID: "", Name: "System Default"}
-	mics = append([]Microphone{defaultMic}, mics...)
+// capturingFromPreRoll reports whether the current recording is fed by the
+// pre-roll stream rather than a stream of its own.
+func (r *Recorder) capturingFromPreRoll() bool {
+	return r.writer != nil && r.stream == nil
+}
 
-	return mics, nil
+// syncPreRoll opens, reopens or closes the pre-roll stream to match the
+// current settings. It must be called with r.mu held and no recording
+// capturing from the stream.
+func (r *Recorder) syncPreRoll() error {
+	if r.preRollDuration <= 0 {
+		r.closePreRoll()
+		return nil
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", r.backend, r.microphone, r.preRollDuration)
+	if r.warm != nil && r.warm.alive() && r.warm.key == key {
+		return nil
+	}
+	r.closePreRoll()
+
+	stream, event, err := r.open()
+	if err != nil {
+		return err
+	}
+	r.writePid(stream)
+	r.warm = newPreRoll(stream, event, key, int(bytesFor(r.preRollDuration)), r.preRollLost)
+	logger.Info("recorder: holding mic open for %s pre-roll", r.preRollDuration)
+	return nil
 }
 
-// findRecBinary locates the rec binary, checking common Homebrew paths
-// since bundled macOS apps don't inherit shell PATH
-func findRecBinary() string {
-	// Check common Homebrew locations first (for bundled app)
-	homebrewPaths := []string{
-		"/opt/homebrew/bin/rec", // Apple Silicon
-		"/usr/local/bin/rec",    // Intel Mac
+// preRollLost reopens the pre-roll stream after it ended on its own, as it
+// does when the recorder process dies or the device goes away. A recording
+// capturing from it carries on from the new stream.
+func (r *Recorder) preRollLost(p *preRoll) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.warm != p {
+		return
 	}
-	for _, p := range homebrewPaths {
-		if _, err := os.Stat(p); err == nil {
-			return p
-		}
+	dst := p.detach()
+	r.closePreRoll()
+
+	// Don't keep reopening a device that fails straight away
+	err := fmt.Errorf("the stream ended after %s", time.Since(p.opened).Round(time.Millisecond))
+	if time.Since(p.opened) >= reopenAfter {
+		logger.Error("recorder: pre-roll stream ended, reopening it")
+		err = r.syncPreRoll()
 	}
-	// Fall back to PATH lookup (works in dev mode)
-	return "rec"
+	if err != nil {
+		r.notifyDevice(DeviceEvent{
+			Device:    r.active,
+			Requested: r.microphone,
+			Warning:   fmt.Sprintf("The microphone stopped and could not be reopened: %v", err),
+		})
+		return
+	}
+	if dst != nil {
+		r.warm.resume(dst)
+	}
+	r.notifyDevice(r.warm.device)
 }
 
-func New(microphone string) *Recorder {
-	homeDir, _ := os.UserHomeDir()
-	cacheDir := filepath.Join(homeDir, ".cache", "jtt")
-	return &Recorder{
-		audioPath:  filepath.Join(cacheDir, "recording.wav"),
-		pidPath:    filepath.Join(cacheDir, "rec.pid"),
-		microphone: microphone,
+func (r *Recorder) closePreRoll() {
+	if r.warm == nil {
+		return
 	}
+	if err := r.warm.close(); err != nil {
+		logger.Error("recorder: failed to close pre-roll stream: %v", err)
+	}
+	r.warm = nil
+	os.Remove(r.pidPath)
 }
 
-func (r *Recorder) SetMicrophone(mic string) {
-	r.microphone = mic
+// OnDeviceChange registers fn to be called when a recording starts on a
+// different device than the previous one, or falls back to the default.
+func (r *Recorder) OnDeviceChange(fn func(DeviceEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onDeviceChange = fn
+}
+
+// SetAutoStop enables silence detection: after speech, once the input stays
+// below thresholdDB for the given duration, the OnSilence callback fires.
+// A zero duration disables it.
+func (r *Recorder) SetAutoStop(thresholdDB float64, after time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.silenceThreshold = thresholdDB
+	r.silenceAfter = after
+}
+
+// OnSilence registers fn to be called, at most once per recording, when
+// auto-stop detects the end of speech. The recording keeps going until Stop.
+func (r *Recorder) OnSilence(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onSilence = fn
+}
+
+// OnLevel registers fn to receive the input level every 100ms of captured
+// audio. It is called from the capture goroutine and must not block.
+func (r *Recorder) OnLevel(fn func(Level)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onLevel = fn
+}
+
+// OnNoInput registers fn to be called, at most once per recording, when the
+// input has been digitally silent for a few seconds.
+func (r *Recorder) OnNoInput(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onNoInput = fn
+}
+
+// SetChunking turns on splitting recordings into chunks at pauses, treating
+// audio quieter than thresholdDB as a pause. It applies from the next
+// recording.
+func (r *Recorder) SetChunking(on bool, thresholdDB float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunking = on
+	r.chunkThreshold = thresholdDB
+}
+
+// OnChunk registers fn to receive chunks of the recording as they are cut,
+// and the last one from Stop. It is called from the capture goroutine, or
+// the goroutine calling Stop, and must not block.
+func (r *Recorder) OnChunk(fn func(Chunk)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChunk = fn
+}
+
+// ActiveDevice returns the device used by the current or last recording.
+func (r *Recorder) ActiveDevice() Microphone {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active
+}
+
+// ListMicrophones returns the input devices of the preferred backend.
+func (r *Recorder) ListMicrophones() ([]Microphone, error) {
+	r.mu.Lock()
+	name := r.backend
+	r.mu.Unlock()
+
+	bs, err := candidates(name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := bs[0].ListMicrophones()
+	if err != nil {
+		return nil, err
+	}
+
+	mics := make([]Microphone, 0, len(raw))
+	names := make(map[string]string, len(raw))
+	for _, m := range raw {
+		if m.ID != "" {
+			m.ID = bs[0].Name() + ":" + m.ID
+		}
+		names[m.ID] = m.Name
+		mics = append(mics, m)
+	}
+
+	r.mu.Lock()
+	r.names = names
+	r.mu.Unlock()
+	return mics, nil
+}
+
+// resolveDevice maps the configured microphone ID to a device of backend b.
+// If the microphone belongs to another backend or is no longer connected, it
+// returns the default device with a warning.
+func (r *Recorder) resolveDevice(b Backend) (string, Microphone, string) {
+	if r.microphone == "" {
+		return "", defaultMicrophone, ""
+	}
+
+	label := r.microphone
+	if name, ok := r.names[r.microphone]; ok {
+		label = name
+	}
+
+	prefix, device, ok := strings.Cut(r.microphone, ":")
+	legacy := !ok || !isBackend(prefix)
+	if legacy {
+		// Configs from before IDs were namespaced hold a bare device ID or name
+		device = r.microphone
+	} else if prefix != b.Name() {
+		return "", defaultMicrophone, fmt.Sprintf("Microphone %q is not available with the %s backend, using the system default", label, b.Name())
+	}
+
+	mics, err := b.ListMicrophones()
+	if err != nil {
+		// Can't enumerate; let the backend try the device
+		return device, Microphone{ID: r.microphone, Name: label}, ""
+	}
+	for _, m := range mics {
+		if m.ID != "" && (m.ID == device || legacy && m.Name == device) {
+			return m.ID, Microphone{ID: b.Name() + ":" + m.ID, Name: m.Name}, ""
+		}
+	}
+	return "", defaultMicrophone, fmt.Sprintf("Microphone %q is not connected, using the system default", label)
 }
 
 func (r *Recorder) AudioPath() string {
@@ -137,12 +355,16 @@ func (r *Recorder) AudioPath() string {
 }
 
 func (r *Recorder) IsRecording() bool {
-	_, err := os.Stat(r.pidPath)
-	return err == nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.writer != nil
 }
 
 func (r *Recorder) Start() error {
-	if r.IsRecording() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.writer != nil {
 		return nil
 	}
 
@@ -151,69 +373,252 @@ func (r *Recorder) Start() error {
 		return err
 	}
 
-	os.Remove(r.audioPath)
+	if r.preRollDuration > 0 {
+		err := r.startFromPreRoll()
+		if err == nil {
+			return nil
+		}
+		logger.Error("recorder: pre-roll unavailable, opening a new stream: %v", err)
+	}
 
-	args := []string{"-q", "-c", "1", "-r", "16000", r.audioPath, "trim", "0", "600"}
-	
-	r.cmd = exec.Command(findRecBinary(), args...)
-	
-	// NOTE: On macOS, sox's coreaudio driver does not support device selection via AUDIODEV
-	// with device names like "MacBook Pro Microphone". It only uses the system default.
-	// Setting an invalid AUDIODEV causes rec to fail silently.
-	// For now, we always use the system default audio input.
-	r.cmd.Stdout = nil
-	r.cmd.Stderr = nil
+	stream, event, err := r.open()
+	if err != nil {
+		return err
+	}
 
-	if err := r.cmd.Start(); err != nil {
+	writer, err := audio.Create(r.audioPath)
+	if err != nil {
+		stream.Close()
 		return err
 	}
 
-	return os.WriteFile(r.pidPath, []byte(fmt.Sprintf("%d", r.cmd.Process.Pid)), 0644)
+	r.writePid(stream)
+	r.stream = stream
+	r.writer = writer
+	r.done = make(chan error, 1)
+	dst := r.outputs(writer)
+	go func() {
+		_, err := io.Copy(dst, stream)
+		r.done <- err
+	}()
+
+	r.notifyDevice(event)
+	return nil
 }
 
-func (r *Recorder) Stop() error {
-	if !r.IsRecording() {
-		return nil
+// startFromPreRoll starts a recording fed by the pre-roll stream, beginning
+// with its buffered audio.
+func (r *Recorder) startFromPreRoll() error {
+	if err := r.syncPreRoll(); err != nil {
+		return err
 	}
 
-	defer os.Remove(r.pidPath)
-
-	pidData, err := os.ReadFile(r.pidPath)
+	writer, err := audio.Create(r.audioPath)
 	if err != nil {
 		return err
 	}
-
-	pid, err := strconv.Atoi(string(pidData))
-	if err != nil {
+	if err := r.warm.attach(r.outputs(writer)); err != nil {
+		writer.Close()
 		return err
 	}
+	r.writer = writer
+	r.notifyDevice(r.warm.device)
+	return nil
+}
 
-	proc, err := os.FindProcess(pid)
+// writePid records the pid of process backends so a crashed app can find
+// them.
+func (r *Recorder) writePid(stream Stream) {
+	if p, ok := stream.(interface{ Pid() int }); ok {
+		if err := os.WriteFile(r.pidPath, []byte(fmt.Sprintf("%d", p.Pid())), 0644); err != nil {
+			logger.Error("recorder: failed to write pid file: %v", err)
+		}
+	}
+}
+
+// outputs returns where captured audio goes: the WAV writer plus the level
+// meter, silence detector and chunker when they are in use.
+func (r *Recorder) outputs(writer *audio.Writer) io.Writer {
+	dst := []io.Writer{writer}
+	r.chunker = nil
+	if r.chunking && r.onChunk != nil {
+		r.chunker = newChunker(r.chunkThreshold, r.onChunk)
+		dst = append(dst, r.chunker)
+	}
+	if r.onLevel != nil || r.onNoInput != nil {
+		dst = append(dst, &levelMeter{onLevel: r.onLevel, onNoInput: r.onNoInput})
+	}
+	if r.silenceAfter > 0 && r.onSilence != nil {
+		dst = append(dst, &silenceWatcher{
+			vad:   audio.NewVAD(r.silenceThreshold),
+			after: r.silenceAfter,
+			fn:    r.onSilence,
+		})
+	}
+	return io.MultiWriter(dst...)
+}
+
+// notifyDevice reports the device a recording started on if it differs from
+// the last one or the configured microphone couldn't be used.
+func (r *Recorder) notifyDevice(event DeviceEvent) {
+	if event.Warning != "" {
+		logger.Error("recorder: %s", event.Warning)
+	}
+	changed := event.Device != r.active
+	r.active = event.Device
+	if (changed || event.Warning != "") && r.onDeviceChange != nil {
+		go r.onDeviceChange(event)
+	}
+}
+
+// open tries each candidate backend in turn, so "auto" falls back to an
+// external recorder if native capture fails.
+func (r *Recorder) open() (Stream, DeviceEvent, error) {
+	bs, err := candidates(r.backend)
 	if err != nil {
+		return nil, DeviceEvent{}, err
+	}
+
+	var lastErr error
+	for _, b := range bs {
+		device, active, warning := r.resolveDevice(b)
+		stream, err := b.Open(device)
+		if err == nil {
+			logger.Info("recorder: capturing from %q with %s backend", active.Name, b.Name())
+			return stream, DeviceEvent{Device: active, Requested: r.microphone, Warning: warning}, nil
+		}
+		logger.Error("recorder: %s backend failed: %v", b.Name(), err)
+		lastErr = err
+	}
+	return nil, DeviceEvent{}, lastErr
+}
+
+// Stop ends the recording and finalizes the WAV file.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.writer == nil {
+		return nil
+	}
+	if r.capturingFromPreRoll() {
+		r.warm.detach()
+		r.flushChunks()
+		err := r.writer.Close()
+		r.writer = nil
+		if syncErr := r.syncPreRoll(); syncErr != nil {
+			logger.Error("recorder: failed to reopen pre-roll stream: %v", syncErr)
+		}
 		return err
 	}
+	defer os.Remove(r.pidPath)
 
-	// Send SIGINT (Ctrl+C) instead of SIGTERM - rec handles this better
-	// and properly finalizes the WAV file header
-	proc.Signal(syscall.SIGINT)
+	closeErr := r.stream.Close()
+	// The copy finishes once the stream has drained its buffered samples
+	copyErr := <-r.done
+	r.flushChunks()
+	writeErr := r.writer.Close()
+	r.stream, r.writer, r.done = nil, nil, nil
 
-	// Wait for the process to actually exit (up to 2 seconds)
-	done := make(chan error, 1)
-	go func() {
-		_, err := proc.Wait()
-		done <- err
-	}()
+	if closeErr != nil {
+		return closeErr
+	}
+	if copyErr != nil {
+		return copyErr
+	}
+	return writeErr
+}
 
-	select {
-	case <-done:
-		// Process exited cleanly
-	case <-time.After(2 * time.Second):
-		// Timeout - force kill
-		proc.Kill()
+// flushChunks hands out the last chunk once capture has ended.
+func (r *Recorder) flushChunks() {
+	if r.chunker != nil {
+		r.chunker.flush()
+		r.chunker = nil
 	}
+}
 
-	// Give filesystem a moment to sync
-	time.Sleep(50 * time.Millisecond)
+// Close stops any recording and releases the mic held open for pre-roll.
+func (r *Recorder) Close() error {
+	err := r.Stop()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closePreRoll()
+	return err
+}
 
-	return nil
+// silenceWatcher runs voice detection on captured audio and calls fn once
+// speech has been followed by enough silence.
+type silenceWatcher struct {
+	vad   *audio.VAD
+	after time.Duration
+	fn    func()
+	fired bool
+}
+
+func (w *silenceWatcher) Write(p []byte) (int, error) {
+	w.vad.Write(p)
+	if !w.fired && w.vad.HeardSpeech() && w.vad.Silence() >= w.after {
+		w.fired = true
+		logger.Info("recorder: %.1fs of silence, auto-stopping", w.vad.Silence().Seconds())
+		go w.fn()
+	}
+	return len(p), nil
+}
+
+// levelMeter reports the input level of captured audio at a fixed interval
+// and watches for a dead input.
+type levelMeter struct {
+	onLevel   func(Level)
+	onNoInput func()
+
+	window   []byte
+	total    int64 // bytes seen
+	silent   int64 // bytes of digital silence in a row
+	reported bool
+}
+
+var levelWindowBytes = int(bytesFor(levelInterval))
+
+func (m *levelMeter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := min(levelWindowBytes-len(m.window), len(p))
+		m.window = append(m.window, p[:take]...)
+		p = p[take:]
+		if len(m.window) == levelWindowBytes {
+			m.report(m.window)
+			m.window = m.window[:0]
+		}
+	}
+	return n, nil
+}
+
+func (m *levelMeter) report(window []byte) {
+	m.total += int64(len(window))
+	rms, peak := audio.Level(window)
+
+	// Allow for a bit of dither; a live mic's noise floor is far above this
+	if peak < 2.0/32768 {
+		m.silent += int64(len(window))
+	} else {
+		m.silent = 0
+	}
+	if !m.reported && m.onNoInput != nil && m.silent >= bytesFor(noInputAfter) {
+		m.reported = true
+		logger.Error("recorder: no input for %s, is the mic muted?", noInputAfter)
+		go m.onNoInput()
+	}
+
+	if m.onLevel != nil {
+		m.onLevel(Level{
+			RMS:     rms,
+			Peak:    peak,
+			Elapsed: float64(m.total) / float64(bytesFor(time.Second)),
+		})
+	}
+}
+
+// bytesFor returns the size of d of captured audio.
+func bytesFor(d time.Duration) int64 {
+	return int64(d.Seconds()*audio.SampleRate) * audio.Channels * audio.BitsPerSample / 8
 }
@@ -0,0 +1,157 @@
+package recorder
+
+import (
+	"fmt"
+	"jtt/internal/audio"
+	"jtt/internal/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Recovery describes what a crashed run left behind.
+type Recovery struct {
+	KilledPid  int         `json:"killedPid,omitempty"`  // orphaned recorder process that was stopped
+	Recordings []Recovered `json:"recordings,omitempty"` // recordings that can still be transcribed, oldest first
+	Duration   float64     `json:"duration,omitempty"`   // of all the recordings
+}
+
+// Recovered is a recording a crashed run never transcribed.
+type Recovered struct {
+	Path     string  `json:"path"`
+	Duration float64 `json:"duration"`
+}
+
+// Add offers another recording.
+func (r *Recovery) Add(rec Recovered) {
+	r.Recordings = append(r.Recordings, rec)
+	r.Duration += rec.Duration
+}
+
+// minRecoverSeconds is the shortest leftover worth offering to transcribe.
+const minRecoverSeconds = 1.0
+
+// recorderCommands are the processes the process backends spawn.
+var recorderCommands = map[string]bool{
+	"rec": true, "sox": true, "parecord": true, "pw-record": true, "pw-cat": true, "arecord": true,
+}
+
+// Recover cleans up after a run that crashed: it stops the recorder process
+// named in the pid file if it is still running, removes stale files, and
+// keeps an unfinished recording aside so it can be transcribed. Recordings
+// still queued for processing in queueDir are offered too, and the chunks
+// streamed from recordings into chunksDir are removed. It must be called
+// before the first recording.
+func (r *Recorder) Recover(queueDir, chunksDir string) (Recovery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rec Recovery
+	if data, err := os.ReadFile(r.pidPath); err == nil {
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil && isRecorderProcess(pid) {
+			logger.Info("recorder: stopping orphaned recorder process %d", pid)
+			killProcess(pid)
+			rec.KilledPid = pid
+		}
+		os.Remove(r.pidPath)
+	}
+
+	// Move an unfinished recording out of the way of the next one and into
+	// the queue, under a name of its own so one from an earlier crash that
+	// was neither transcribed nor discarded is kept too
+	info, err := audio.ReadInfo(r.audioPath)
+	switch {
+	case err != nil || !info.Unfinished:
+		// Nothing there, or a finished recording whose dictation already ran
+	case info.Duration() < minRecoverSeconds:
+		os.Remove(r.audioPath)
+	default:
+		if err := audio.Finalize(r.audioPath); err != nil {
+			return rec, err
+		}
+		if err := os.MkdirAll(queueDir, 0755); err != nil {
+			return rec, err
+		}
+		path := filepath.Join(queueDir, fmt.Sprintf("recovered-%d.wav", time.Now().UnixNano()))
+		if err := os.Rename(r.audioPath, path); err != nil {
+			return rec, err
+		}
+		logger.Info("recorder: recovered %.1fs unfinished recording at %s", info.Duration(), path)
+	}
+
+	// Each chunk is also in its recording
+	if err := os.RemoveAll(chunksDir); err != nil {
+		logger.Error("recorder: failed to remove streamed chunks: %v", err)
+	}
+
+	// Everything in the queue was left by a crash, as is a recording kept
+	// where earlier versions put it
+	queued, _ := filepath.Glob(filepath.Join(queueDir, "*.wav"))
+	legacy := filepath.Join(filepath.Dir(r.audioPath), "recovered.wav")
+	for _, p := range append(queued, legacy) {
+		info, err := audio.ReadInfo(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil && info.Unfinished {
+			err = audio.Finalize(p)
+		}
+		if err != nil || info.Duration() < minRecoverSeconds {
+			os.Remove(p)
+			continue
+		}
+		rec.Add(Recovered{Path: p, Duration: info.Duration()})
+	}
+	sort.SliceStable(rec.Recordings, func(i, k int) bool {
+		return modTime(rec.Recordings[i].Path).Before(modTime(rec.Recordings[k].Path))
+	})
+	if len(rec.Recordings) > 0 {
+		logger.Info("recorder: %d recordings left untranscribed", len(rec.Recordings))
+	}
+	return rec, nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// isRecorderProcess reports whether pid is a live process running one of
+// the recorder commands, so a pid reused by an unrelated process is left
+// alone.
+func isRecorderProcess(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
+	if err != nil {
+		return false
+	}
+	return recorderCommands[filepath.Base(strings.TrimSpace(string(out)))]
+}
+
+// killProcess interrupts pid, then kills it if it hasn't exited after two
+// seconds.
+func killProcess(pid int) {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+	p.Signal(os.Interrupt)
+	for i := 0; i < 20; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if p.Signal(syscall.Signal(0)) != nil {
+			return
+		}
+	}
+	p.Kill()
+}
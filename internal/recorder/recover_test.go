@@ -0,0 +1,111 @@
+package recorder
+
+import (
+	"jtt/internal/audio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeWAV(t *testing.T, path string, d time.Duration, finish bool) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := audio.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(make([]byte, bytesFor(d))); err != nil {
+		t.Fatal(err)
+	}
+	if finish {
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	r, _ := newFakeRecorder(t)
+	dir := filepath.Dir(r.AudioPath())
+	queueDir := filepath.Join(dir, "jobs")
+	chunksDir := filepath.Join(dir, "chunks")
+
+	old := filepath.Join(queueDir, "a.wav")
+	writeWAV(t, old, 2*time.Second, true)
+	os.Chtimes(old, time.Now().Add(-time.Minute), time.Now().Add(-time.Minute))
+	writeWAV(t, filepath.Join(queueDir, "b.wav"), 3*time.Second, true)
+	writeWAV(t, filepath.Join(queueDir, "short.wav"), 100*time.Millisecond, true)
+	writeWAV(t, filepath.Join(chunksDir, "b", "0.wav"), time.Second, true)
+	writeWAV(t, r.AudioPath(), 4*time.Second, false)
+
+	rec, err := r.Recover(queueDir, chunksDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.Recordings) != 3 {
+		t.Fatalf("recovered %+v, want 3 recordings", rec.Recordings)
+	}
+	want := []Recovered{
+		{Path: old, Duration: 2},
+		{Path: filepath.Join(queueDir, "b.wav"), Duration: 3},
+	}
+	for i := range want {
+		if rec.Recordings[i] != want[i] {
+			t.Errorf("recording %d is %+v, want %+v", i, rec.Recordings[i], want[i])
+		}
+	}
+	// The unfinished recording joins the queue
+	if last := rec.Recordings[2]; filepath.Dir(last.Path) != queueDir || last.Duration != 4 {
+		t.Errorf("unfinished recording recovered as %+v", last)
+	}
+	if rec.Duration != 9 {
+		t.Errorf("duration %.2fs, want 9s", rec.Duration)
+	}
+
+	for _, gone := range []string{r.AudioPath(), filepath.Join(queueDir, "short.wav"), chunksDir} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", gone)
+		}
+	}
+}
+
+// TestRecoverTwice checks that a second crash before the first recording was
+// dealt with keeps both.
+func TestRecoverTwice(t *testing.T) {
+	r, _ := newFakeRecorder(t)
+	dir := filepath.Dir(r.AudioPath())
+	queueDir, chunksDir := filepath.Join(dir, "jobs"), filepath.Join(dir, "chunks")
+
+	for _, d := range []time.Duration{2 * time.Second, 3 * time.Second} {
+		writeWAV(t, r.AudioPath(), d, false)
+		if _, err := r.Recover(queueDir, chunksDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec, err := r.Recover(queueDir, chunksDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Recordings) != 2 || rec.Duration != 5 {
+		t.Errorf("recovered %+v, want both recordings", rec.Recordings)
+	}
+}
+
+func TestRecoverNothing(t *testing.T) {
+	r, _ := newFakeRecorder(t)
+	dir := filepath.Dir(r.AudioPath())
+	// A finished recording already went through its dictation
+	writeWAV(t, r.AudioPath(), 2*time.Second, true)
+
+	rec, err := r.Recover(filepath.Join(dir, "jobs"), filepath.Join(dir, "chunks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Recordings) != 0 {
+		t.Errorf("recovered %+v", rec.Recordings)
+	}
+}
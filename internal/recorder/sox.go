@@ -0,0 +1,205 @@
+package recorder
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// soxBackend records with sox, streaming raw PCM over stdout. The default
+// input uses rec; on macOS a specific device is opened by name with
+// `sox -t coreaudio <name>` (AUDIODEV does not work with coreaudio names).
+// Elsewhere only the default input is supported.
+type soxBackend struct{}
+
+func (soxBackend) Name() string { return "sox" }
+
+func (soxBackend) Available() bool {
+	_, err := exec.LookPath(findRecBinary())
+	return err == nil
+}
+
+func (soxBackend) ListMicrophones() ([]Microphone, error) {
+	if runtime.GOOS != "darwin" {
+		return []Microphone{defaultMicrophone}, nil
+	}
+	return listMicrophonesFromSystemProfiler()
+}
+
+func (soxBackend) Open(device string) (Stream, error) {
+	output := []string{
+		"-c", "1", "-r", "16000", "-b", "16", "-e", "signed-integer",
+		"-t", "raw", "-",
+	}
+	if device != "" && runtime.GOOS == "darwin" {
+		args := append([]string{"-q", "-t", "coreaudio", device}, output...)
+		return startProcess(exec.Command(findSoxBinary(), args...))
+	}
+	return startProcess(exec.Command(findRecBinary(), append([]string{"-q"}, output...)...))
+}
+
+func listMicrophonesFromSystemProfiler() ([]Microphone, error) {
+	cmd := exec.Command("system_profiler", "SPAudioDataType")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var mics []Microphone
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+
+	var currentDevice string
+	var hasInput bool
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		// Detect device names: lines with 8 spaces indent ending with ":"
+		// (not property lines which have 10+ spaces)
+		if strings.HasPrefix(line, "        ") && !strings.HasPrefix(line, "          ") &&
+			strings.HasSuffix(trimmed, ":") &&
+			!strings.Contains(trimmed, "Devices:") {
+			// Save previous device if it had input
+			if hasInput && currentDevice != "" {
+				found := false
+				for _, m := range mics {
+					if m.Name == currentDevice {
+						found = true
+						break
+					}
+				}
+				if !found {
+					mics = append(mics, Microphone{
+						ID:   currentDevice,
+						Name: currentDevice,
+					})
+				}
+			}
+			currentDevice = strings.TrimSuffix(trimmed, ":")
+			hasInput = false
+		}
+
+		// Check if device has input capabilities
+		if strings.Contains(trimmed, "Input Channels:") || strings.Contains(trimmed, "Input Source:") {
+			hasInput = true
+		}
+	}
+
+	// Don't forget the last device
+	if hasInput && currentDevice != "" {
+		found := false
+		for _, m := range mics {
+			if m.Name == currentDevice {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mics = append(mics, Microphone{
+				ID:   currentDevice,
+				Name: currentDevice,
+			})
+		}
+	}
+
+	// Always include "default" option
+	mics = append([]Microphone{defaultMicrophone}, mics...)
+
+	return mics, nil
+}
+
+// findRecBinary locates the rec binary, checking common Homebrew paths
+// since bundled macOS apps don't inherit shell PATH
+func findRecBinary() string {
+	// Check common Homebrew locations first (for bundled app)
+	homebrewPaths := []string{
+		"/opt/homebrew/bin/rec", // Apple Silicon
+		"/usr/local/bin/rec",    // Intel Mac
+	}
+	for _, p := range homebrewPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	// Fall back to PATH lookup (works in dev mode)
+	return "rec"
+}
+
+// findSoxBinary locates sox next to rec, which is a link to it
+func findSoxBinary() string {
+	rec := findRecBinary()
+	if rec == "rec" {
+		return "sox"
+	}
+	return filepath.Join(filepath.Dir(rec), "sox")
+}
+
+// processStream reads PCM from a recorder process's stdout.
+type processStream struct {
+	cmd *exec.Cmd
+	out *os.File
+}
+
+func startProcess(cmd *exec.Cmd) (*processStream, error) {
+	// Use our own pipe rather than cmd.StdoutPipe so Wait doesn't close it
+	// before the remaining samples are read
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = nil
+
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	w.Close()
+
+	return &processStream{cmd: cmd, out: r}, nil
+}
+
+// Pid returns the recorder process ID, which Recorder records in a pid file.
+func (p *processStream) Pid() int {
+	return p.cmd.Process.Pid
+}
+
+func (p *processStream) Read(b []byte) (int, error) {
+	n, err := p.out.Read(b)
+	if err == io.EOF {
+		p.out.Close()
+	}
+	return n, err
+}
+
+// Close stops the process and waits for it to exit. Samples still in the
+// pipe stay readable until EOF.
+func (p *processStream) Close() error {
+	// Send SIGINT (Ctrl+C) instead of SIGTERM - recorders handle this
+	// better and flush their remaining audio
+	p.cmd.Process.Signal(syscall.SIGINT)
+
+	// Wait for the process to actually exit (up to 2 seconds)
+	done := make(chan error, 1)
+	go func() {
+		done <- p.cmd.Wait()
+	}()
+
+	select {
+	case <-done:
+		// Process exited cleanly
+	case <-time.After(2 * time.Second):
+		// Timeout - force kill
+		p.cmd.Process.Kill()
+		<-done
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package state implements the dictation state machine shared by the hotkey,
+// tray menu, settings UI and control socket.
+package state
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type State string
+
+const (
+	Idle       State = "idle"
+	Recording  State = "recording"
+	Processing State = "processing"
+	Error      State = "error"
+	Cancelled  State = "cancelled"
+)
+
+// transitions lists the states reachable from each state. Processing means
+// recordings are queued for transcription, so a new recording may start
+// meanwhile. Error and Cancelled are transient: they are reported to
+// listeners and then return to Idle, or to Processing while jobs remain.
+var transitions = map[State][]State{
+	Idle:       {Recording},
+	Recording:  {Processing, Cancelled, Error},
+	Processing: {Idle, Recording, Cancelled, Error},
+	Error:      {Idle, Processing},
+	Cancelled:  {Idle, Processing},
+}
+
+// TransitionError is returned when a transition is not allowed from the
+// current state, e.g. starting a recording while one is being processed.
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot go from %s to %s", e.From, e.To)
+}
+
+// CanTransition reports whether to is reachable from from.
+func CanTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Machine holds the current state. All methods are safe for concurrent use.
+type Machine struct {
+	// mu serialises transitions and their notifications; current is read
+	// without it so Current never waits on a listener
+	mu       sync.Mutex
+	current  atomic.Value // State
+	onChange func(State)
+}
+
+// New returns a machine in the Idle state. onChange, if non-nil, is called
+// after every successful transition while the machine is locked, so listeners
+// observe transitions in order; it must not make transitions itself. Current
+// never blocks, so listeners, and anything a listener waits on, may call it.
+func New(onChange func(State)) *Machine {
+	m := &Machine{onChange: onChange}
+	m.current.Store(Idle)
+	return m
+}
+
+// Current returns the current state.
+func (m *Machine) Current() State {
+	return m.current.Load().(State)
+}
+
+// set moves to s and notifies the listener. It must be called with m.mu held.
+func (m *Machine) set(s State) {
+	m.current.Store(s)
+	if m.onChange != nil {
+		m.onChange(s)
+	}
+}
+
+// Transition moves to the given state, or returns a *TransitionError if that
+// is not allowed from the current state.
+func (m *Machine) Transition(to State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if from := m.Current(); !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	m.set(to)
+	return nil
+}
+
+// ResetTo forces the machine to to, which must be Idle or Processing,
+// through the transient state via if it is reachable, e.g. Error, notifying
+// listeners of each step. It is used to recover from failures, e.g. when a
+// recording fails while earlier ones are still queued, and is a no-op when
+// already in to.
+func (m *Machine) ResetTo(via, to State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Current() == to {
+		return
+	}
+	if via != "" && CanTransition(m.Current(), via) {
+		m.set(via)
+	}
+	m.set(to)
+}
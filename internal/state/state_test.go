@@ -0,0 +1,115 @@
+package state
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	m := New(nil)
+	for _, to := range []State{Recording, Processing, Recording, Processing, Idle} {
+		if err := m.Transition(to); err != nil {
+			t.Fatalf("transition to %s: %v", to, err)
+		}
+	}
+
+	err := m.Transition(Processing)
+	var terr *TransitionError
+	if !errors.As(err, &terr) || terr.From != Idle || terr.To != Processing {
+		t.Fatalf("got %v, want a TransitionError from idle to processing", err)
+	}
+	if got := m.Current(); got != Idle {
+		t.Errorf("failed transition changed the state to %s", got)
+	}
+}
+
+func TestResetTo(t *testing.T) {
+	var seen []State
+	m := New(func(s State) { seen = append(seen, s) })
+	m.Transition(Recording)
+	m.ResetTo(Error, Processing)
+	m.ResetTo(Error, Processing)
+
+	want := []State{Recording, Error, Processing}
+	if len(seen) != len(want) {
+		t.Fatalf("notified %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("notified %v, want %v", seen, want)
+		}
+	}
+}
+
+// TestConcurrent hammers the machine from several goroutines, as the hotkey,
+// tray, control socket and job queue do. Run it with -race.
+func TestConcurrent(t *testing.T) {
+	var m *Machine
+	var mu sync.Mutex
+	var seen []State
+	m = New(func(s State) {
+		// Listeners run in order, and see the state they are told about
+		if got := m.Current(); got != s {
+			t.Errorf("listener told %s while the state is %s", s, got)
+		}
+		mu.Lock()
+		seen = append(seen, s)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 500; n++ {
+				switch (i + n) % 5 {
+				case 0:
+					m.Transition(Recording)
+				case 1:
+					m.Transition(Processing)
+				case 2:
+					m.ResetTo(Error, Idle)
+				case 3:
+					m.ResetTo(Cancelled, Processing)
+				default:
+					m.Current()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) == 0 {
+		t.Fatal("no transitions made")
+	}
+	if last := seen[len(seen)-1]; last != m.Current() {
+		t.Errorf("last notification %s, but the state is %s", last, m.Current())
+	}
+}
+
+// TestCurrentFromListener checks that a listener may wait on something that
+// reads the state, as the tray menu does when it waits on the recorder while
+// the capture goroutine reports input levels.
+func TestCurrentFromListener(t *testing.T) {
+	var m *Machine
+	m = New(func(s State) {
+		done := make(chan State)
+		go func() { done <- m.Current() }()
+		select {
+		case got := <-done:
+			if got != s {
+				t.Errorf("read %s from another goroutine, want %s", got, s)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Current blocked while a listener ran")
+		}
+	})
+	if err := m.Transition(Recording); err != nil {
+		t.Fatal(err)
+	}
+}
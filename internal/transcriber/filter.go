@@ -0,0 +1,213 @@
+package transcriber
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rule recognises a hallucination in a segment's text. An Exact rule drops
+// segments that say exactly that, ignoring case and punctuation; a Regex
+// rule removes every match, dropping the segment if nothing is left.
+type Rule struct {
+	Name  string
+	Exact string
+	Regex *regexp.Regexp
+}
+
+// Filtered is a piece of text the filter removed, and why.
+type Filtered struct {
+	Text   string  `json:"text"`
+	Reason string  `json:"reason"`
+	Start  float64 `json:"start,omitempty"` // segment timing, if known
+	End    float64 `json:"end,omitempty"`
+}
+
+// Filter removes what whisper tends to make up on silence and noise.
+type Filter struct {
+	Rules []Rule
+	// NoSpeechThreshold drops segments whisper itself thinks are more
+	// likely than this not to be speech. 0 disables the check.
+	NoSpeechThreshold float64
+	// MaxRepeats is how many times in a row a phrase or segment may repeat
+	// before the rest is treated as whisper looping. 0 disables the check.
+	MaxRepeats int
+}
+
+// Filter defaults.
+const (
+	DefaultNoSpeechThreshold = 0.6
+	DefaultMaxRepeats        = 3
+	// maxPhraseWords is the longest phrase repetition detection looks for
+	maxPhraseWords = 10
+)
+
+// BuiltinRules are hallucinations whisper is known for: its training data
+// was full of video subtitles. Phrase rules only match a whole segment, so
+// the same words inside real dictation are kept; bracketed rules only match
+// the annotations whisper emits.
+var BuiltinRules = []Rule{
+	{Name: "silence", Exact: "you"},
+	{Name: "video outro", Regex: regexp.MustCompile(`(?i)^\s*(?:thank you|thanks)(?: so much| very much)? for (?:watching|listening|viewing)[.!]?\s*$`)},
+	{Name: "video outro", Regex: regexp.MustCompile(`(?i)^\s*(?:please |don't forget to )?(?:like and )?subscribe(?: to (?:my|our|the|this) channel)?[.!]?\s*$`)},
+	{Name: "subtitle credit", Regex: regexp.MustCompile(`(?i)^\s*(?:subtitles|captions|subtitled|transcribed|translated|transcription|translation)(?: provided)? by(?:\s+[\w.-]+){1,4}[.!]?\s*$`)},
+	{Name: "subtitle credit", Regex: regexp.MustCompile(`(?i)^.*\bamara\.org\b.*$`)},
+	{Name: "annotation", Regex: regexp.MustCompile(`(?i)[\[(*]\s*(?:\w+\s+)?(?:blank_audio|no speech|silence|music|applause|laughter|laughs|laughing|inaudible|noise|static|sighs?|coughs?|beeps?|typing|footsteps|wind|breathing|clears throat)\s*[\])*]`)},
+	{Name: "music", Regex: regexp.MustCompile(`[♪♫]+`)},
+}
+
+// NewFilter returns a filter with the built-in rules followed by rules, and
+// the default thresholds.
+func NewFilter(rules ...Rule) *Filter {
+	return &Filter{
+		Rules:             append(append([]Rule(nil), BuiltinRules...), rules...),
+		NoSpeechThreshold: DefaultNoSpeechThreshold,
+		MaxRepeats:        DefaultMaxRepeats,
+	}
+}
+
+// Apply filters segments, in order: no-speech segments, rule matches, then
+// repetitions. prompt, if set, is the initial prompt, which whisper may echo
+// back on silence. It returns the remaining segments and what was removed.
+func (f *Filter) Apply(segments []Segment, prompt string) ([]Segment, []Filtered) {
+	var kept []Segment
+	var log []Filtered
+	drop := func(seg Segment, text, reason string) {
+		log = append(log, Filtered{Text: strings.TrimSpace(text), Reason: reason, Start: seg.Start, End: seg.End})
+	}
+
+	rules := f.Rules
+	if prompt != "" {
+		rules = append(rules[:len(rules):len(rules)], Rule{Name: "prompt echo", Exact: prompt})
+	}
+
+	for _, seg := range segments {
+		if f.NoSpeechThreshold > 0 && seg.NoSpeechProb > f.NoSpeechThreshold {
+			drop(seg, seg.Text, fmt.Sprintf("no speech (%.0f%% likely)", seg.NoSpeechProb*100))
+			continue
+		}
+		for _, r := range rules {
+			if r.Exact != "" && normalize(seg.Text) == normalize(r.Exact) {
+				drop(seg, seg.Text, r.Name)
+				seg.Text = ""
+				break
+			}
+			if r.Regex == nil {
+				continue
+			}
+			for _, m := range r.Regex.FindAllString(seg.Text, -1) {
+				drop(seg, m, r.Name)
+			}
+			seg.Text = r.Regex.ReplaceAllString(seg.Text, "")
+		}
+		if normalize(seg.Text) == "" {
+			continue
+		}
+		kept = append(kept, seg)
+	}
+
+	if f.MaxRepeats > 0 {
+		var repeats []Filtered
+		kept, repeats = f.dropRepeatedSegments(kept)
+		log = append(log, repeats...)
+		for i := range kept {
+			var removed []Filtered
+			kept[i].Text, removed = f.collapsePhrases(kept[i])
+			log = append(log, removed...)
+		}
+	}
+	return kept, log
+}
+
+// dropRepeatedSegments keeps one of each run of more than MaxRepeats
+// segments with the same text.
+func (f *Filter) dropRepeatedSegments(segments []Segment) ([]Segment, []Filtered) {
+	var kept []Segment
+	var log []Filtered
+	for i := 0; i < len(segments); {
+		run := 1
+		for i+run < len(segments) && normalize(segments[i+run].Text) == normalize(segments[i].Text) {
+			run++
+		}
+		if run > f.MaxRepeats {
+			kept = append(kept, segments[i])
+			last := segments[i+run-1]
+			log = append(log, Filtered{
+				Text:   strings.TrimSpace(segments[i].Text),
+				Reason: fmt.Sprintf("segment repeated %d times", run),
+				Start:  segments[i+1].Start,
+				End:    last.End,
+			})
+		} else {
+			kept = append(kept, segments[i:i+run]...)
+		}
+		i += run
+	}
+	return kept, log
+}
+
+// collapsePhrases shortens any phrase of up to maxPhraseWords words that
+// repeats more than MaxRepeats times in a row to a single occurrence.
+func (f *Filter) collapsePhrases(seg Segment) (string, []Filtered) {
+	words := strings.Fields(seg.Text)
+	var log []Filtered
+	changed := false
+	for n := 1; n <= maxPhraseWords; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			run := 1
+			for i+(run+1)*n <= len(words) && samePhrase(words[i:i+n], words[i+run*n:i+(run+1)*n]) {
+				run++
+			}
+			if run <= f.MaxRepeats {
+				continue
+			}
+			log = append(log, Filtered{
+				Text:   strings.Join(words[i:i+n], " "),
+				Reason: fmt.Sprintf("phrase repeated %d times", run),
+				Start:  seg.Start,
+				End:    seg.End,
+			})
+			words = append(words[:i+n], words[i+run*n:]...)
+			changed = true
+		}
+	}
+	if !changed {
+		return seg.Text, nil
+	}
+	// Keep the leading space whisper puts on segments so they join up
+	text := strings.Join(words, " ")
+	if strings.HasPrefix(seg.Text, " ") {
+		text = " " + text
+	}
+	return text, log
+}
+
+func samePhrase(a, b []string) bool {
+	for i := range a {
+		if normalize(a[i]) != normalize(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize lowercases s and drops everything but letters, digits and
+// single spaces, so "Thanks for watching!" matches "thanks for watching".
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package transcriber
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func joined(segments []Segment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(seg.Text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func kept(f *Filter, segments []Segment) []Segment {
+	kept, _ := f.Apply(segments, "")
+	return kept
+}
+
+func TestFilterRemovesHallucinations(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{" you", ""},
+		{" You.", ""},
+		{" Thank you for watching!", ""},
+		{" Thanks so much for listening.", ""},
+		{" Please subscribe.", ""},
+		{" Don't forget to like and subscribe to my channel!", ""},
+		{" Subtitles by the Amara.org community", ""},
+		{" Transcription by CastingWords", ""},
+		{" [BLANK_AUDIO]", ""},
+		{" [ Silence ]", ""},
+		{" ♪ ♪", ""},
+		{" (upbeat music) Let's get started.", "Let's get started."},
+		{" *laughs* That was close.", "That was close."},
+		{" Send the report [MUSIC] by Friday.", "Send the report  by Friday."},
+	}
+	f := NewFilter()
+	for _, tt := range tests {
+		kept, removed := f.Apply([]Segment{{Text: tt.text}}, "")
+		if got := joined(kept); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+		if len(removed) == 0 {
+			t.Errorf("%q: nothing reported as filtered", tt.text)
+		}
+	}
+}
+
+func TestFilterKeepsDictation(t *testing.T) {
+	for _, text := range []string{
+		" Thanks for listening, I'll send the notes tomorrow.",
+		" Thank you for watching the kids on Saturday.",
+		" Translation from German is due Friday.",
+		" Transcript from the standup: we ship on Monday.",
+		" Please subscribe to the release mailing list.",
+		" Set items[0] to the first result.",
+		" Translated by hand, the contract reads differently in every clause.",
+		" Did you?",
+		" The music was too loud.",
+	} {
+		kept, removed := NewFilter().Apply([]Segment{{Text: text}}, "")
+		if got := joined(kept); got != strings.TrimSpace(text) {
+			t.Errorf("%q became %q, removed %+v", text, got, removed)
+		}
+	}
+}
+
+func TestFilterNoSpeech(t *testing.T) {
+	segments := []Segment{
+		{Text: " Hello.", NoSpeechProb: 0.1},
+		{Text: " Hmm.", NoSpeechProb: 0.9},
+	}
+	kept, removed := NewFilter().Apply(segments, "")
+	if got := joined(kept); got != "Hello." {
+		t.Errorf("got %q", got)
+	}
+	if len(removed) != 1 || removed[0].Text != "Hmm." {
+		t.Errorf("removed %+v", removed)
+	}
+
+	f := NewFilter()
+	f.NoSpeechThreshold = 0
+	if kept, _ := f.Apply(segments, ""); len(kept) != 2 {
+		t.Errorf("a zero threshold still dropped segments: %+v", kept)
+	}
+}
+
+func TestFilterRepeats(t *testing.T) {
+	f := NewFilter()
+
+	repeated := []Segment{{Text: " Okay."}, {Text: " Okay."}, {Text: " Okay."}, {Text: " Okay."}, {Text: " Done."}}
+	if got := joined(kept(f, repeated)); got != "Okay. Done." {
+		t.Errorf("repeated segments: got %q", got)
+	}
+
+	// Up to MaxRepeats in a row is still speech
+	allowed := []Segment{{Text: " No."}, {Text: " No."}, {Text: " No."}}
+	if got := joined(kept(f, allowed)); got != "No. No. No." {
+		t.Errorf("allowed repeats: got %q", got)
+	}
+
+	looping := []Segment{{Text: " I think that I think that I think that I think that I think that we should go."}}
+	if got := joined(kept(f, looping)); got != "I think that we should go." {
+		t.Errorf("looping phrase: got %q", got)
+	}
+}
+
+func TestFilterPromptEcho(t *testing.T) {
+	prompt := "Kubernetes, JTTService."
+	kept, removed := NewFilter().Apply([]Segment{{Text: " Kubernetes, JTTService."}}, prompt)
+	if len(kept) != 0 || len(removed) != 1 || removed[0].Reason != "prompt echo" {
+		t.Errorf("kept %+v, removed %+v", kept, removed)
+	}
+
+	kept, _ = NewFilter().Apply([]Segment{{Text: " Deploy JTTService to Kubernetes."}}, prompt)
+	if got := joined(kept); got != "Deploy JTTService to Kubernetes." {
+		t.Errorf("dictation using the vocabulary became %q", got)
+	}
+}
+
+func TestFilterCustomRules(t *testing.T) {
+	f := NewFilter(
+		Rule{Name: "outro", Exact: "See you next time"},
+		Rule{Name: "filler", Regex: regexp.MustCompile(`(?i)\s*\bum+\b,?`)},
+	)
+	kept, removed := f.Apply([]Segment{
+		{Text: " See you next time!"},
+		{Text: " So, um, the build is green."},
+	}, "")
+	if got := joined(kept); got != "So, the build is green." {
+		t.Errorf("got %q", got)
+	}
+	if len(removed) != 2 || removed[0].Reason != "outro" || removed[1].Reason != "filler" {
+		t.Errorf("removed %+v", removed)
+	}
+}
+
+func TestFilterKeepsInput(t *testing.T) {
+	segments := []Segment{{Text: " go go go go go home"}, {Text: " Thanks for watching!"}}
+	NewFilter().Apply(segments, "")
+	if segments[0].Text != " go go go go go home" || segments[1].Text != " Thanks for watching!" {
+		t.Errorf("Apply changed its input: %+v", segments)
+	}
+}
@@ -0,0 +1,227 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Default server addresses: whisper-server's own default, and the usual
+// port of local OpenAI-compatible servers such as faster-whisper-server.
+const (
+	DefaultWhisperServerURL = "http://127.0.0.1:8080"
+	DefaultOpenAIURL        = "http://127.0.0.1:8000"
+	DefaultOpenAIModel      = "whisper-1"
+)
+
+// whisperServer posts files to a long-running whisper.cpp whisper-server,
+// which keeps the model loaded between dictations.
+type whisperServer struct {
+	url string
+}
+
+// NewWhisperServer returns a backend that posts to the whisper-server at
+// url. An empty url means DefaultWhisperServerURL.
+func NewWhisperServer(url string) Backend {
+	if url == "" {
+		url = DefaultWhisperServerURL
+	}
+	return whisperServer{url: endpoint(url, "/inference")}
+}
+
+func (whisperServer) Name() string { return BackendWhisperServer }
+
+// Model is the backend name: the server picks the model at startup and
+// doesn't report it.
+func (whisperServer) Model() string { return BackendWhisperServer }
+
+func (b whisperServer) Transcribe(ctx context.Context, req Request) (*TranscribeResult, error) {
+	fields := map[string]string{
+		"language":        req.Language,
+		"response_format": "verbose_json",
+		"temperature":     "0",
+	}
+	if req.Prompt != "" {
+		fields["prompt"] = req.Prompt
+	}
+	ctx, cancel := withTimeout(ctx, b.Name(), req.AudioPath)
+	defer cancel()
+	return postAudio(ctx, b.url, req.AudioPath, fields, "")
+}
+
+// openAI posts files to an OpenAI-compatible /v1/audio/transcriptions
+// endpoint, e.g. faster-whisper-server or LocalAI.
+type openAI struct {
+	url    string
+	model  string
+	apiKey string
+}
+
+// NewOpenAI returns a backend that posts to the OpenAI-compatible server at
+// url, asking for model. Empty values mean DefaultOpenAIURL and
+// DefaultOpenAIModel; apiKey is only sent if set.
+func NewOpenAI(url, model, apiKey string) Backend {
+	if url == "" {
+		url = DefaultOpenAIURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return openAI{url: endpoint(url, "/v1/audio/transcriptions"), model: model, apiKey: apiKey}
+}
+
+func (openAI) Name() string { return BackendOpenAI }
+
+func (b openAI) Model() string { return b.model }
+
+func (b openAI) Transcribe(ctx context.Context, req Request) (*TranscribeResult, error) {
+	fields := map[string]string{
+		"model":           b.model,
+		"response_format": "verbose_json",
+	}
+	// The API takes ISO-639-1 codes and detects the language when none is given
+	if req.Language != "auto" {
+		fields["language"] = req.Language
+	}
+	if req.Prompt != "" {
+		fields["prompt"] = req.Prompt
+	}
+	ctx, cancel := withTimeout(ctx, b.Name(), req.AudioPath)
+	defer cancel()
+	return postAudio(ctx, b.url, req.AudioPath, fields, b.apiKey)
+}
+
+// endpoint appends path to a server's base URL, unless the URL already
+// points at it or at the /v1 prefix of it.
+func endpoint(base, path string) string {
+	base = strings.TrimRight(base, "/")
+	if strings.HasSuffix(base, path) {
+		return base
+	}
+	if strings.HasSuffix(base, "/v1") && strings.HasPrefix(path, "/v1/") {
+		return base + strings.TrimPrefix(path, "/v1")
+	}
+	return base + path
+}
+
+// postAudio uploads audioPath as a multipart form with fields and returns the
+// text, language and segments of the verbose JSON response.
+func postAudio(ctx context.Context, url, audioPath string, fields map[string]string, apiKey string) (*TranscribeResult, error) {
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(audioPath))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, fmt.Errorf("transcription server not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, err
+	}
+
+	// whisper-server reports some errors with a 200 and an "error" field;
+	// OpenAI-style servers nest them as {"error": {"message": ...}}
+	var result struct {
+		Text     string `json:"text"`
+		Language string `json:"language"`
+		Segments []struct {
+			Start        float64 `json:"start"`
+			End          float64 `json:"end"`
+			Text         string  `json:"text"`
+			AvgLogprob   float64 `json:"avg_logprob"`
+			NoSpeechProb float64 `json:"no_speech_prob"`
+		} `json:"segments"`
+		Error json.RawMessage `json:"error"`
+	}
+	jsonErr := json.Unmarshal(data, &result)
+	if msg := errorMessage(result.Error); msg != "" {
+		return nil, fmt.Errorf("transcription server: %s", msg)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transcription server: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("transcription server: invalid response: %w", jsonErr)
+	}
+	// Servers that don't report the language used the requested one
+	language := result.Language
+	if language == "" {
+		language = fields["language"]
+	}
+	out := &TranscribeResult{Text: result.Text, Language: language}
+	for _, seg := range result.Segments {
+		out.Segments = append(out.Segments, Segment{
+			Start:        seg.Start,
+			End:          seg.End,
+			Text:         seg.Text,
+			Probability:  math.Exp(seg.AvgLogprob),
+			NoSpeechProb: seg.NoSpeechProb,
+		})
+	}
+	return out, nil
+}
+
+// errorMessage extracts the message from an "error" field that is either a
+// string or an object with a "message".
+func errorMessage(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var msg string
+	if json.Unmarshal(raw, &msg) == nil {
+		return msg
+	}
+	var obj struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.Message != "" {
+		return obj.Message
+	}
+	return string(raw)
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package transcriber
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killTree makes cancelling cmd kill everything it started too, not just cmd
+// itself, by running it in its own process group.
+func killTree(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
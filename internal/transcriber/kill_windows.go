@@ -0,0 +1,14 @@
+package transcriber
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killTree makes cancelling cmd kill everything it started too, not just cmd
+// itself.
+func killTree(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+}
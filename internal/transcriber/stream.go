@@ -0,0 +1,207 @@
+package transcriber
+
+import (
+	"context"
+	"fmt"
+	"jtt/internal/audio"
+	"jtt/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stream transcribes a recording chunk by chunk while it is still being
+// made, so that once it stops only the last chunk is left to transcribe.
+// Chunks are transcribed one at a time, in order.
+type Stream struct {
+	t      *Transcriber
+	dir    string
+	filter func(audioPath string) error
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	last      chan struct{} // closed once the last added chunk is done
+	parts     []*TranscribeResult
+	seconds   float64
+	err       error
+	onPartial func(text string)
+}
+
+// NewStream starts a stream writing chunks into dir. filter, if set, runs
+// on each chunk file before it is transcribed, e.g. to preprocess it.
+func (t *Transcriber) NewStream(dir string, filter func(audioPath string) error) *Stream {
+	ctx, cancel := context.WithCancel(context.Background())
+	last := make(chan struct{})
+	close(last)
+	return &Stream{t: t, dir: dir, filter: filter, ctx: ctx, cancel: cancel, last: last}
+}
+
+// OnPartial registers fn to receive the text transcribed so far after each
+// chunk, with hallucinations already filtered out.
+func (s *Stream) OnPartial(fn func(text string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPartial = fn
+}
+
+// Add queues a chunk of 16 kHz mono PCM starting at offset into the
+// recording. It doesn't wait for the chunk to be transcribed.
+func (s *Stream) Add(index int, offset time.Duration, pcm []byte) {
+	path := filepath.Join(s.dir, fmt.Sprintf("chunk-%03d.wav", index))
+	if err := writeChunk(path, pcm); err != nil {
+		s.fail(err)
+		return
+	}
+
+	s.mu.Lock()
+	prev := s.last
+	done := make(chan struct{})
+	s.last = done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer os.Remove(path)
+		<-prev
+		s.transcribe(path, offset)
+	}()
+}
+
+func writeChunk(path string, pcm []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	w, err := audio.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(pcm); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// transcribe runs one chunk through the backend, shifting its segments onto
+// the recording's timeline.
+func (s *Stream) transcribe(path string, offset time.Duration) {
+	if s.ctx.Err() != nil || s.failed() {
+		return
+	}
+	if s.filter != nil {
+		if err := s.filter(path); err != nil {
+			logger.Error("transcriber: chunk filter failed: %v", err)
+		}
+	}
+
+	start := time.Now()
+	result, err := s.t.backend.Transcribe(s.ctx, Request{AudioPath: path, Language: s.t.language, Prompt: s.t.prompt})
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.fail(err)
+		}
+		return
+	}
+	for i := range result.Segments {
+		result.Segments[i].Start += offset.Seconds()
+		result.Segments[i].End += offset.Seconds()
+	}
+
+	s.mu.Lock()
+	s.parts = append(s.parts, result)
+	s.seconds += time.Since(start).Seconds()
+	text, _, _ := s.t.clean(stitch(s.parts))
+	onPartial := s.onPartial
+	s.mu.Unlock()
+
+	logger.Info("transcriber: chunk at %.1fs done in %.2fs", offset.Seconds(), time.Since(start).Seconds())
+	if onPartial != nil {
+		onPartial(text)
+	}
+}
+
+func (s *Stream) fail(err error) {
+	logger.Error("transcriber: streaming failed: %v", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Stream) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err != nil
+}
+
+// Transcribe waits for the remaining chunks and returns the stitched result,
+// filtered like a whole-file transcription. audioPath is the full recording:
+// if any chunk failed it is transcribed in one go instead. Cancelling ctx
+// cancels the stream.
+func (s *Stream) Transcribe(ctx context.Context, audioPath string) (*TranscribeResult, error) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+
+	waited := time.Now()
+	select {
+	case <-last:
+	case <-ctx.Done():
+		s.Cancel()
+		return nil, ctx.Err()
+	}
+	os.Remove(s.dir)
+
+	s.mu.Lock()
+	err, parts, seconds := s.err, s.parts, s.seconds
+	s.mu.Unlock()
+	if err != nil {
+		logger.Info("transcriber: transcribing the whole recording instead")
+		return s.t.Transcribe(ctx, audioPath)
+	}
+
+	logger.Info("transcriber: %d chunks, %.2fs after recording stopped", len(parts), time.Since(waited).Seconds())
+	return s.t.finish(stitch(parts), seconds), nil
+}
+
+// Cancel stops transcribing; chunks not yet started are skipped. The chunk
+// directory is removed once the running one has stopped.
+func (s *Stream) Cancel() {
+	s.cancel()
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	go func() {
+		<-last
+		os.Remove(s.dir)
+	}()
+}
+
+// stitch joins chunk results in order. Segments are kept only if every
+// chunk had them, since the text is derived from them.
+func stitch(parts []*TranscribeResult) *TranscribeResult {
+	out := &TranscribeResult{}
+	var texts []string
+	segmented := true
+	for _, p := range parts {
+		if t := strings.TrimSpace(p.Text); t != "" {
+			texts = append(texts, t)
+		}
+		if out.Language == "" {
+			out.Language = p.Language
+		}
+		if len(p.Segments) == 0 && strings.TrimSpace(p.Text) != "" {
+			segmented = false
+		}
+		out.Segments = append(out.Segments, p.Segments...)
+	}
+	out.Text = strings.Join(texts, " ")
+	if !segmented {
+		out.Segments = nil
+	}
+	return out
+}
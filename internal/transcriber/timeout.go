@@ -0,0 +1,47 @@
+package transcriber
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jtt/internal/audio"
+)
+
+// A backend may take timeoutBase, for loading the model, plus
+// timeoutPerSecond for every second of audio before it's given up on. That is
+// several times slower than whisper runs on a modest CPU, so hitting it means
+// something hung.
+const (
+	timeoutBase      = 2 * time.Minute
+	timeoutPerSecond = 5 * time.Second
+)
+
+// TimeoutError is returned when a backend takes longer than the timeout for
+// the audio it was given.
+type TimeoutError struct {
+	Backend string
+	Timeout time.Duration
+	Audio   time.Duration // length of the audio; 0 if it couldn't be read
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Audio == 0 {
+		return fmt.Sprintf("%s timed out after %s", e.Backend, e.Timeout)
+	}
+	return fmt.Sprintf("%s timed out after %s on %s of audio", e.Backend, e.Timeout, e.Audio.Round(time.Second))
+}
+
+// Unwrap makes a TimeoutError match context.DeadlineExceeded.
+func (e *TimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+// withTimeout bounds ctx by the timeout for the audio at audioPath. When it
+// expires, context.Cause(ctx) is a *TimeoutError naming backend.
+func withTimeout(ctx context.Context, backend, audioPath string) (context.Context, context.CancelFunc) {
+	var length time.Duration
+	if seconds, err := audio.Duration(audioPath); err == nil {
+		length = time.Duration(seconds * float64(time.Second))
+	}
+	timeout := timeoutBase + time.Duration(length.Seconds()*float64(timeoutPerSecond))
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Backend: backend, Timeout: timeout, Audio: length})
+}
@@ -1,100 +1,192 @@
 package transcriber
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 )
 
 type TranscribeResult struct {
+	// Text is the segments' text joined, or the backend's text if it
+	// returned no segments
 	Text    string
 	Seconds float64
+	// Language is the language code whisper transcribed in: the requested
+	// one, or the detected one for "auto"
+	Language string
+	Segments []Segment
+	// Vocabulary is the terms whisper was prompted with
+	Vocabulary []string
+	// Filtered is what the hallucination filter removed
+	Filtered []Filtered
 }
 
-type Transcriber struct {
-	modelPath            string
-	filterHallucinations bool
+// Segment is a stretch of speech as whisper split it up.
+type Segment struct {
+	Start        float64 `json:"start"` // seconds from the start of the audio
+	End          float64 `json:"end"`
+	Text         string  `json:"text"`
+	Probability  float64 `json:"probability"`  // average token probability, 0 to 1
+	NoSpeechProb float64 `json:"noSpeechProb"` // whisper's estimate that there was no speech
 }
 
-// findWhisperBinary locates the whisper-cli binary, checking common Homebrew paths
-// since bundled macOS apps don't inherit shell PATH
-func findWhisperBinary() string {
-	homebrewPaths := []string{
-		"/opt/homebrew/bin/whisper-cli", // Apple Silicon
-		"/usr/local/bin/whisper-cli",    // Intel Mac
-	}
-	for _, p := range homebrewPaths {
-		if _, err := os.Stat(p); err == nil {
-			return p
-		}
-	}
-	return "whisper-cli"
+// Backend runs speech recognition on a 16 kHz mono WAV file.
+type Backend interface {
+	Name() string
+	// Model names the model the backend transcribes with, for history.
+	Model() string
+	// Transcribe returns the text and language; Seconds is filled in by
+	// the Transcriber.
+	Transcribe(ctx context.Context, req Request) (*TranscribeResult, error)
 }
 
-func New(modelPath string, filterHallucinations bool) *Transcriber {
-	return &Transcriber{modelPath: modelPath, filterHallucinations: filterHallucinations}
+// Request is one file for a Backend to transcribe.
+type Request struct {
+	AudioPath string
+	Language  string // language code, or "auto" to detect it
+	Prompt    string // initial prompt, e.g. from a vocabulary; may be empty
+	// OnProgress, if set, receives progress in percent. Backends that can't
+	// report progress never call it.
+	OnProgress func(percent int)
 }
 
-func (t *Transcriber) Transcribe(audioPath string) (*TranscribeResult, error) {
-	if _, err := os.Stat(t.modelPath); os.IsNotExist(err) {
-		return nil, err
+// Backend names, for Config.TranscriptionBackend.
+const (
+	BackendWhisperCLI    = "whisper-cli"
+	BackendWhisperServer = "whisper-server"
+	BackendOpenAI        = "openai"
+)
+
+// Settings selects and configures a Backend.
+type Settings struct {
+	Backend   string // one of the Backend* names; empty means whisper-cli
+	ModelPath string // model file for whisper-cli
+	URL       string // server address for the HTTP backends
+	Model     string // model name sent to OpenAI-compatible servers
+	APIKey    string // bearer token for OpenAI-compatible servers, if needed
+}
+
+// NewBackend returns the backend s selects.
+func NewBackend(s Settings) (Backend, error) {
+	switch s.Backend {
+	case "", BackendWhisperCLI:
+		return NewWhisperCLI(s.ModelPath), nil
+	case BackendWhisperServer:
+		return NewWhisperServer(s.URL), nil
+	case BackendOpenAI:
+		return NewOpenAI(s.URL, s.Model, s.APIKey), nil
 	}
+	return nil, fmt.Errorf("unknown transcription backend: %s", s.Backend)
+}
 
-	outputBase := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+// Transcriber runs a Backend and post-processes its text.
+type Transcriber struct {
+	backend    Backend
+	language   string
+	filter     *Filter
+	onProgress func(percent int)
+	prompt     string
+	vocabulary []string
+}
 
-	cmd := exec.Command(findWhisperBinary(),
-		"-m", t.modelPath,
-		"-f", audioPath,
-		"--no-timestamps",
-		"--language", "en",
-		"--output-txt",
-		"--output-file", outputBase,
-		"--no-fallback",
-		"-et", "2.4",
-		"-lpt", "-1.0",
-	)
+// New returns a transcriber using backend. language is a whisper language
+// code, or "auto" to detect it; empty means English. A nil filter keeps
+// hallucinations.
+func New(backend Backend, language string, filter *Filter) *Transcriber {
+	if language == "" {
+		language = "en"
+	}
+	return &Transcriber{backend: backend, language: language, filter: filter}
+}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// OnProgress registers fn to receive the backend's progress, in percent.
+func (t *Transcriber) OnProgress(fn func(percent int)) {
+	t.onProgress = fn
+}
 
-	start := time.Now()
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		if errMsg == "" {
-			errMsg = "unknown error"
-		}
-		return nil, fmt.Errorf("%w: %s", err, errMsg)
+// SetVocabulary prompts whisper with terms it should expect, such as names
+// and jargon, as many as fit in the prompt budget. It returns the terms that
+// fit.
+func (t *Transcriber) SetVocabulary(terms []string) []string {
+	t.prompt, t.vocabulary = VocabularyPrompt(terms)
+	if dropped := len(terms) - len(t.vocabulary); dropped > 0 {
+		log.Printf("transcriber: vocabulary too long, left out %d terms", dropped)
 	}
-	elapsed := time.Since(start).Seconds()
+	return t.vocabulary
+}
 
-	txtPath := outputBase + ".txt"
-	data, err := os.ReadFile(txtPath)
+// Model names the model the backend transcribes with.
+func (t *Transcriber) Model() string {
+	return t.backend.Model()
+}
+
+// Transcribe runs the backend on audioPath. Cancelling ctx aborts it.
+func (t *Transcriber) Transcribe(ctx context.Context, audioPath string) (*TranscribeResult, error) {
+	start := time.Now()
+	result, err := t.backend.Transcribe(ctx, Request{
+		AudioPath:  audioPath,
+		Language:   t.language,
+		Prompt:     t.prompt,
+		OnProgress: t.onProgress,
+	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
+	return t.finish(result, time.Since(start).Seconds()), nil
+}
 
-	text := strings.TrimSpace(string(data))
-
-	// Filter out known whisper hallucinations on silence/noise
-	if t.filterHallucinations {
-		normalized := strings.ToLower(strings.TrimRight(text, ".,!?"))
-		if normalized == "you" {
-			log.Println("transcriber: filtered hallucination on silence/noise")
-			text = ""
-		}
+// finish filters hallucinations from a backend's raw result, derives its
+// text and normalises the language.
+func (t *Transcriber) finish(result *TranscribeResult, elapsed float64) *TranscribeResult {
+	text, segments, filtered := t.clean(result)
+	for _, f := range filtered {
+		log.Printf("transcriber: filtered %q (%s)", f.Text, f.Reason)
 	}
 
 	if text == "" {
 		log.Println("transcriber: no audio detected (empty transcription)")
 	}
 
+	language := languageCode(result.Language)
+	if language == "" && t.language != "auto" {
+		language = t.language
+	}
 	return &TranscribeResult{
-		Text:    text,
-		Seconds: elapsed,
-	}, nil
+		Text:       text,
+		Seconds:    elapsed,
+		Language:   language,
+		Segments:   segments,
+		Vocabulary: t.vocabulary,
+		Filtered:   filtered,
+	}
+}
+
+// clean filters hallucinations from a backend's raw result and derives its
+// text from what's left.
+func (t *Transcriber) clean(result *TranscribeResult) (string, []Segment, []Filtered) {
+	// Text without segments is filtered as one segment
+	segments := result.Segments
+	unsegmented := len(segments) == 0
+	if unsegmented {
+		segments = []Segment{{Text: result.Text}}
+	}
+
+	var filtered []Filtered
+	if t.filter != nil {
+		segments, filtered = t.filter.Apply(segments, t.prompt)
+	}
+
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(seg.Text)
+	}
+	if unsegmented {
+		segments = nil
+	}
+	return strings.TrimSpace(b.String()), segments, filtered
 }
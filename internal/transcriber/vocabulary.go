@@ -0,0 +1,49 @@
+package transcriber
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// PromptTokens is how much of the initial prompt whisper uses: half its
+// 448-token text context. Anything longer is cut from the front.
+const PromptTokens = 224
+
+// VocabularyPrompt builds whisper's initial prompt from terms, in order,
+// keeping as many as fit in PromptTokens. Blank and repeated terms are
+// skipped. It returns the prompt and the terms in it.
+func VocabularyPrompt(terms []string) (string, []string) {
+	var used []string
+	seen := make(map[string]bool)
+	tokens := 0
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		// One more for the comma between terms
+		n := estimateTokens(term) + 1
+		if tokens+n > PromptTokens {
+			break
+		}
+		seen[key] = true
+		used = append(used, term)
+		tokens += n
+	}
+	if len(used) == 0 {
+		return "", nil
+	}
+	return strings.Join(used, ", ") + ".", used
+}
+
+// estimateTokens guesses how many tokens whisper's tokenizer splits s into.
+// Common English words are a token each, but names and code identifiers
+// split into pieces of a few characters, so this errs on the high side.
+func estimateTokens(s string) int {
+	n := 0
+	for _, word := range strings.Fields(s) {
+		n += (utf8.RuneCountInString(word) + 2) / 3
+	}
+	return n
+}
@@ -0,0 +1,184 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// findWhisperBinary locates the whisper-cli binary, checking common Homebrew paths
+// since bundled macOS apps don't inherit shell PATH
+func findWhisperBinary() string {
+	homebrewPaths := []string{
+		"/opt/homebrew/bin/whisper-cli", // Apple Silicon
+		"/usr/local/bin/whisper-cli",    // Intel Mac
+	}
+	for _, p := range homebrewPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return "whisper-cli"
+}
+
+// whisperCLI runs whisper.cpp's whisper-cli once per file. It needs nothing
+// running in the background, but loads the model from disk every time.
+type whisperCLI struct {
+	modelPath string
+}
+
+// NewWhisperCLI returns a backend that runs whisper-cli with the model at
+// modelPath.
+func NewWhisperCLI(modelPath string) Backend {
+	return whisperCLI{modelPath: modelPath}
+}
+
+func (whisperCLI) Name() string { return BackendWhisperCLI }
+
+func (b whisperCLI) Model() string { return filepath.Base(b.modelPath) }
+
+// Transcribe runs whisper-cli on req.AudioPath. Cancelling ctx, or running
+// past the timeout for the audio's length, kills whisper-cli and anything it
+// started.
+func (b whisperCLI) Transcribe(ctx context.Context, req Request) (*TranscribeResult, error) {
+	if _, err := os.Stat(b.modelPath); os.IsNotExist(err) {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, b.Name(), req.AudioPath)
+	defer cancel()
+
+	outputBase := strings.TrimSuffix(req.AudioPath, filepath.Ext(req.AudioPath))
+
+	// The full JSON output has segment timings and per-token probabilities
+	args := []string{
+		"-m", b.modelPath,
+		"-f", req.AudioPath,
+		"--language", req.Language,
+		"--output-json-full",
+		"--output-file", outputBase,
+		"--no-fallback",
+		"-et", "2.4",
+		"-lpt", "-1.0",
+	}
+	if req.Prompt != "" {
+		args = append(args, "--prompt", req.Prompt)
+	}
+	if req.OnProgress != nil {
+		args = append(args, "--print-progress")
+	}
+	cmd := exec.CommandContext(ctx, findWhisperBinary(), args...)
+	killTree(cmd)
+	// Don't wait for output from a killed process's children
+	cmd.WaitDelay = 5 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if req.OnProgress != nil {
+		cmd.Stderr = &progressWriter{out: &stderr, fn: req.OnProgress}
+	}
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = "unknown error"
+		}
+		return nil, fmt.Errorf("%w: %s", err, errMsg)
+	}
+
+	jsonPath := outputBase + ".json"
+	defer os.Remove(jsonPath)
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseCLIOutput(data)
+}
+
+// cliOutput is the part of whisper-cli's --output-json-full file we use.
+type cliOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // milliseconds
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text   string `json:"text"`
+		Tokens []struct {
+			Text string  `json:"text"`
+			P    float64 `json:"p"`
+		} `json:"tokens"`
+		NoSpeechProb float64 `json:"no_speech_prob"`
+	} `json:"transcription"`
+}
+
+func parseCLIOutput(data []byte) (*TranscribeResult, error) {
+	var out cliOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid whisper-cli output: %w", err)
+	}
+
+	result := &TranscribeResult{Language: out.Result.Language}
+	for _, t := range out.Transcription {
+		seg := Segment{
+			Start:        float64(t.Offsets.From) / 1000,
+			End:          float64(t.Offsets.To) / 1000,
+			Text:         t.Text,
+			NoSpeechProb: t.NoSpeechProb,
+		}
+		// Average over text tokens only; special tokens like [_BEG_] and
+		// timestamps carry no meaning
+		n := 0
+		for _, tok := range t.Tokens {
+			if strings.HasPrefix(tok.Text, "[_") {
+				continue
+			}
+			seg.Probability += tok.P
+			n++
+		}
+		if n > 0 {
+			seg.Probability /= float64(n)
+		}
+		result.Segments = append(result.Segments, seg)
+	}
+	return result, nil
+}
+
+var progressPattern = regexp.MustCompile(`progress\s*=\s*(\d+)%`)
+
+// progressWriter passes whisper-cli's stderr through to out, reporting the
+// "progress = N%" lines printed with --print-progress as they arrive.
+type progressWriter struct {
+	out  io.Writer
+	fn   func(percent int)
+	line []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if m := progressPattern.FindSubmatch(w.line[:i]); m != nil {
+			percent, _ := strconv.Atoi(string(m[1]))
+			w.fn(percent)
+		}
+		w.line = w.line[i+1:]
+	}
+	return w.out.Write(p)
+}
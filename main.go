@@ -1,20 +1,33 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"jtt/internal/accessibility"
+	"jtt/internal/audio"
 	"jtt/internal/cleaner"
 	"jtt/internal/config"
+	"jtt/internal/control"
+	"jtt/internal/export"
+	"jtt/internal/history"
+	"jtt/internal/jobs"
 	"jtt/internal/logger"
 	"jtt/internal/media"
+	"jtt/internal/pipeline"
+	"jtt/internal/preprocess"
 	"jtt/internal/recorder"
+	"jtt/internal/state"
 	"jtt/internal/transcriber"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
@@ -33,43 +46,87 @@ var iconRecording []byte
 //go:embed assets/icon-processing.png
 var iconProcessing []byte
 
-type AppState string
-
-const (
-	StateIdle       AppState = "idle"
-	StateRecording  AppState = "recording"
-	StateProcessing AppState = "processing"
-)
-
 type JTTApp struct {
 	app           *application.App
 	systray       *application.SystemTray
 	window        *application.WebviewWindow
-	cfg           *config.Config
 	recorder      *recorder.Recorder
-	state         AppState
-	history       []config.TranscriptionEntry
+	machine       *state.Machine
+	history       *history.Store
+
+	// Dictations record from source and are processed by the stages
+	// pipelineFor assembles: the recorder and newPipeline, or fakes in tests
+	source      pipeline.AudioSource
+	pipelineFor func(cfg *config.Config, profile string) *pipeline.Pipeline
+
+	// cfg is replaced as a whole, never changed in place, so a dictation
+	// can take one snapshot and use it throughout; cfgMu serialises updates
+	cfg   atomic.Pointer[config.Config]
+	cfgMu sync.Mutex
+
+	// The tray status item shows the elapsed time while recording; it is
+	// updated from the capture goroutine, outside the state machine's lock
+	statusItem atomic.Pointer[application.MenuItem]
+	elapsed    atomic.Int64
+
+	// recovered are recordings interrupted by a crash, waiting to be
+	// transcribed or discarded
+	recovered atomic.Pointer[recorder.Recovery]
+
+	// failure is what went wrong with the last dictation, shown in the tray
+	// until the next recording starts
+	failure atomic.Pointer[string]
+
+	// queue transcribes finished recordings in order while new ones start
+	queue *jobs.Queue
+
+	// With streaming on, stream transcribes the current recording's chunks
+	// as they are cut; once it stops, its stream moves to streams under the
+	// job's ID until the job runs
+	stream  atomic.Pointer[transcriber.Stream]
+	streams sync.Map
+
+	// mu guards mediaWasPlaying and profile, which are touched from the
+	// hotkey goroutines, service calls and tray click handlers. It also
+	// makes moving between Processing and Idle atomic with adding jobs to
+	// the queue.
+	mu              sync.Mutex
 	mediaWasPlaying bool
+	profile         string // profile of the current recording; empty for the main hotkey
 }
 
+var errDictationCancelled = errors.New("dictation cancelled")
+
 func main() {
+	if len(os.Args) > 1 {
+		if code, ok := runCLI(os.Args[1:]); ok {
+			os.Exit(code)
+		}
+	}
+
 	if err := logger.Init(); err != nil {
 		log.Printf("Failed to init logger: %v", err)
 	}
 	defer logger.Close()
 
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Error("Failed to load config: %v", err)
-		cfg = config.DefaultConfig()
-	}
+	jtt := newJTTApp(loadConfig())
 
-	jtt := &JTTApp{
-		cfg:      cfg,
-		recorder: recorder.New(cfg.Microphone),
-		state:    StateIdle,
-		history:  make([]config.TranscriptionEntry, 0, 5),
+	// Serve the control socket so the jtt CLI can drive the tray app too.
+	// It is claimed before the mic is touched, so a second launch leaves the
+	// running instance's recording alone
+	ready := make(chan struct{})
+	srv, err := jtt.claimControl(ready)
+	if errors.Is(err, control.ErrAlreadyRunning) {
+		logger.Error("Not starting: %v", err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to start control socket: %v", err)
+	} else {
+		defer srv.Close()
 	}
+	jtt.startAudio()
+	defer jtt.recorder.Close()
 
 	app := application.New(application.Options{
 		Name:        "JTT",
@@ -87,6 +144,7 @@ func main() {
 
 	jtt.app = app
 	jtt.setupSystray()
+	close(ready)
 
 	// Check accessibility permissions on startup (only prompt if not already granted)
 	go func() {
@@ -112,17 +170,70 @@ func main() {
 		jtt.setupHotkey()
 	}()
 
-	err = app.Run()
-	if err != nil {
+	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
+func loadConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load config: %v", err)
+		cfg = config.DefaultConfig()
+	}
+	return cfg
+}
+
+func newJTTApp(cfg *config.Config) *JTTApp {
+	store, err := history.Open(history.DefaultPath(), cfg.HistoryMaxEntries, cfg.HistoryMaxAgeDays)
+	if err != nil {
+		logger.Error("Failed to load history, keeping it in memory only: %v", err)
+		store, _ = history.Open("", cfg.HistoryMaxEntries, cfg.HistoryMaxAgeDays)
+	}
+	return newJTTAppWith(cfg, store, recorder.New(cfg.AudioBackend, cfg.Microphone))
+}
+
+// newJTTAppWith returns an app using the given history store and recorder.
+func newJTTAppWith(cfg *config.Config, store *history.Store, rec *recorder.Recorder) *JTTApp {
+	j := &JTTApp{
+		recorder: rec,
+		history:  store,
+	}
+	j.source = rec
+	j.pipelineFor = j.newPipeline
+	j.cfg.Store(cfg)
+	j.machine = state.New(j.onStateChange)
+	j.queue = jobs.New(j.runJob, j.onJobsChange, j.onQueueIdle)
+	j.recorder.OnDeviceChange(j.onDeviceChange)
+	j.recorder.OnSilence(j.onSilence)
+	j.recorder.OnLevel(j.onLevel)
+	j.recorder.OnNoInput(j.onNoInput)
+	j.recorder.SetAutoStop(cfg.SilenceThreshold(), cfg.SilenceStop())
+	j.recorder.OnChunk(j.onChunk)
+	j.recorder.SetChunking(cfg.StreamTranscription, cfg.SilenceThreshold())
+	return j
+}
+
+// startAudio prepares the recorder for dictation. It is left out of one-off
+// CLI commands so they don't touch the mic.
+func (j *JTTApp) startAudio() {
+	// Clean up after a crash before the mic is opened again
+	if rec, err := j.recorder.Recover(j.queueDir(), j.chunksDir()); err != nil {
+		logger.Error("Failed to recover previous recording: %v", err)
+	} else if len(rec.Recordings) > 0 {
+		j.recovered.Store(&rec)
+	}
+
+	if err := j.recorder.SetPreRoll(time.Duration(j.config().PreRollMs) * time.Millisecond); err != nil {
+		logger.Error("Failed to open mic for pre-roll: %v", err)
+	}
+}
+
 func (j *JTTApp) setupSystray() {
 	j.systray = j.app.SystemTray.New()
 	j.systray.SetIcon(iconIdle)
 
-	j.updateMenu()
+	j.updateMenu(state.Idle)
 }
 
 func (j *JTTApp) createWindow() *application.WebviewWindow {
@@ -152,19 +263,51 @@ func (j *JTTApp) showSettings() {
 }
 
 func (j *JTTApp) setupHotkey() {
-	if len(j.cfg.Hotkey.Keys) == 0 {
+	cfg := j.config()
+	if len(cfg.Hotkey.Keys) == 0 {
 		log.Printf("No hotkey configured")
 		return
 	}
 
-	key := j.cfg.Hotkey.Keys[0]
-	mods := parseModifiers(j.cfg.Hotkey.Modifiers)
+	if len(cfg.CancelHotkey.Keys) > 0 {
+		go j.registerCancelHotkey(parseModifiers(cfg.CancelHotkey.Modifiers), parseKey(cfg.CancelHotkey.Keys[0]))
+	}
+
+	for _, p := range cfg.Profiles {
+		if len(p.Hotkey.Keys) > 0 {
+			go j.registerHotkey(parseModifiers(p.Hotkey.Modifiers), parseKey(p.Hotkey.Keys[0]), p.Name)
+		}
+	}
+
+	key := cfg.Hotkey.Keys[0]
+	mods := parseModifiers(cfg.Hotkey.Modifiers)
 	k := parseKey(key)
 
-	j.registerHotkey(mods, k)
+	j.registerHotkey(mods, k, "")
+}
+
+// registerCancelHotkey listens for the cancel hotkey and aborts the current
+// recording or transcription each time it is pressed.
+func (j *JTTApp) registerCancelHotkey(mods []hotkey.Modifier, key hotkey.Key) {
+	hk := hotkey.New(mods, key)
+	if err := hk.Register(); err != nil {
+		log.Printf("Failed to register cancel hotkey: %v", err)
+		return
+	}
+
+	log.Printf("Cancel hotkey registered: %v + %v", mods, key)
+
+	for range hk.Keydown() {
+		log.Printf("Cancel hotkey pressed")
+		if err := j.CancelRecording(); err != nil {
+			log.Printf("Cancel ignored: %v", err)
+		}
+	}
 }
 
-func (j *JTTApp) registerHotkey(mods []hotkey.Modifier, key hotkey.Key) {
+// registerHotkey records while the hotkey is held, using the named profile's
+// settings, or the main settings for the empty name.
+func (j *JTTApp) registerHotkey(mods []hotkey.Modifier, key hotkey.Key, profile string) {
 	hk := hotkey.New(mods, key)
 	err := hk.Register()
 	if err != nil {
@@ -177,17 +320,21 @@ func (j *JTTApp) registerHotkey(mods []hotkey.Modifier, key hotkey.Key) {
 	// Listen for keydown (start recording)
 	<-hk.Keydown()
 	log.Printf("Hotkey pressed - starting recording")
-	j.StartRecording()
+	if err := j.startRecording(profile); err != nil {
+		log.Printf("Hotkey start ignored: %v", err)
+	}
 
 	// Listen for keyup (stop recording)
 	<-hk.Keyup()
 	log.Printf("Hotkey released - stopping recording")
-	j.StopRecording()
+	if _, err := j.stopRecording(); err != nil {
+		log.Printf("Hotkey stop ignored: %v", err)
+	}
 
 	hk.Unregister()
 
 	// Re-register to listen again
-	j.registerHotkey(mods, key)
+	j.registerHotkey(mods, key, profile)
 }
 
 func parseModifiers(mods []string) []hotkey.Modifier {
@@ -195,11 +342,11 @@ func parseModifiers(mods []string) []hotkey.Modifier {
 	for _, m := range mods {
 		switch m {
 		case "cmd", "command":
-			result = append(result, hotkey.ModCmd)
+			result = append(result, modCmd)
 		case "ctrl", "control":
 			result = append(result, hotkey.ModCtrl)
 		case "alt", "option":
-			result = append(result, hotkey.ModOption)
+			result = append(result, modAlt)
 		case "shift":
 			result = append(result, hotkey.ModShift)
 		}
@@ -317,34 +464,91 @@ func parseKey(key string) hotkey.Key {
 }
 
 func (j *JTTApp) updateHotkey(modifiers []string, key string) error {
-	j.cfg.Hotkey.Modifiers = modifiers
-	j.cfg.Hotkey.Keys = []string{key}
-	return j.cfg.Save()
+	return j.updateConfig(func(cfg *config.Config) {
+		cfg.Hotkey = config.HotkeyConfig{Modifiers: modifiers, Keys: []string{key}}
+	})
 	// Note: Hotkey will be re-registered on next app restart
 }
 
-func (j *JTTApp) updateMenu() {
+// config returns the current settings. Callers must not modify them.
+func (j *JTTApp) config() *config.Config {
+	return j.cfg.Load()
+}
+
+// updateConfig applies fn to a copy of the current settings, then makes the
+// copy current and saves it.
+func (j *JTTApp) updateConfig(fn func(cfg *config.Config)) error {
+	j.cfgMu.Lock()
+	defer j.cfgMu.Unlock()
+	cfg := *j.config()
+	fn(&cfg)
+	j.cfg.Store(&cfg)
+	return cfg.Save()
+}
+
+func (j *JTTApp) updateMenu(current state.State) {
 	menu := j.app.NewMenu()
 
 	statusLabel := "Ready"
-	if j.state == StateRecording {
-		statusLabel = "Recording..."
-	} else if j.state == StateProcessing {
+	if j.recorder.PreRollActive() {
+		// Make it obvious the mic is live even when not recording
+		statusLabel = "Ready (mic open)"
+	}
+	if f := j.failure.Load(); f != nil {
+		statusLabel = *f
+	}
+	switch current {
+	case state.Recording:
+		j.elapsed.Store(0)
+		statusLabel = recordingLabel(0)
+	case state.Processing:
 		statusLabel = "Processing..."
+		if n := j.queue.Pending(); n > 1 {
+			statusLabel = fmt.Sprintf("Processing %d recordings...", n)
+		}
+	case state.Error:
+		statusLabel = "Error"
+	case state.Cancelled:
+		statusLabel = "Cancelled"
 	}
 
 	status := menu.Add(statusLabel)
 	status.SetEnabled(false)
+	j.statusItem.Store(status)
 
 	menu.AddSeparator()
 
-	if j.state == StateIdle {
+	if current == state.Idle || current == state.Processing {
 		menu.Add("Start Recording").OnClick(func(ctx *application.Context) {
 			j.StartRecording()
 		})
-	} else if j.state == StateRecording {
+	} else if current == state.Recording {
 		menu.Add("Stop Recording").OnClick(func(ctx *application.Context) {
-			j.StopRecording()
+			j.stopRecording()
+		})
+	}
+
+	if current == state.Processing {
+		menu.Add("Cancel All").OnClick(func(ctx *application.Context) {
+			j.CancelRecording()
+		})
+	} else if current == state.Recording {
+		menu.Add("Cancel").OnClick(func(ctx *application.Context) {
+			j.CancelRecording()
+		})
+	}
+
+	if rec := j.recovered.Load(); rec != nil && current == state.Idle {
+		menu.AddSeparator()
+		what := "Interrupted Recording"
+		if n := len(rec.Recordings); n > 1 {
+			what = fmt.Sprintf("%d Interrupted Recordings", n)
+		}
+		menu.Add(fmt.Sprintf("Transcribe %s (%.0fs)", what, rec.Duration)).OnClick(func(ctx *application.Context) {
+			go j.TranscribeRecovered()
+		})
+		menu.Add("Discard " + what).OnClick(func(ctx *application.Context) {
+			j.DiscardRecovered()
 		})
 	}
 
@@ -363,132 +567,797 @@ func (j *JTTApp) updateMenu() {
 	j.systray.SetMenu(menu)
 }
 
-func (j *JTTApp) updateState(state AppState) {
-	j.state = state
+// refreshMenu rebuilds the tray menu after a change that doesn't go through
+// the state machine. While recording the menu is left alone so the elapsed
+// time keeps ticking.
+func (j *JTTApp) refreshMenu() {
+	if j.app == nil {
+		return
+	}
+	if current := j.machine.Current(); current == state.Idle || current == state.Processing {
+		j.updateMenu(current)
+	}
+}
+
+func recordingLabel(seconds int) string {
+	return fmt.Sprintf("Recording %d:%02d", seconds/60, seconds%60)
+}
 
-	switch state {
-	case StateIdle:
+// onStateChange is called by the state machine after every transition, in
+// order, to update the tray and notify the frontend.
+func (j *JTTApp) onStateChange(current state.State) {
+	logger.Info("State: %s", current)
+
+	// Headless daemon mode has no tray or frontend to update
+	if j.app == nil {
+		return
+	}
+
+	switch current {
+	case state.Idle, state.Cancelled:
 		j.systray.SetTemplateIcon(iconIdle)
-	case StateRecording:
+	case state.Recording:
 		j.systray.SetTemplateIcon(iconRecording)
-	case StateProcessing:
+	case state.Processing, state.Error:
 		j.systray.SetTemplateIcon(iconProcessing)
 	}
 
-	j.updateMenu()
+	j.updateMenu(current)
 	// Emit event to frontend
-	j.app.Event.Emit("state-change", string(state))
+	j.app.Event.Emit("state-change", string(current))
 }
 
-func (j *JTTApp) StartRecording() error {
-	if j.state != StateIdle {
-		return nil
+// onDeviceChange tells the frontend which microphone is recording, including
+// when the selected one was unavailable and the default was used instead.
+func (j *JTTApp) onDeviceChange(event recorder.DeviceEvent) {
+	logger.Info("Microphone: %s", event.Device.Name)
+
+	if j.app == nil {
+		return
+	}
+	j.app.Event.Emit("device-change", event)
+	// The mic held open for pre-roll may have closed
+	j.refreshMenu()
+}
+
+// onSilence ends a hands-free recording once the speaker has stopped talking.
+func (j *JTTApp) onSilence() {
+	if j.machine.Current() != state.Recording {
+		return
+	}
+	if _, err := j.stopRecording(); err != nil {
+		logger.Error("Auto-stop failed: %v", err)
+	}
+}
+
+// onLevel forwards the input level to the frontend and ticks the elapsed time
+// in the tray once a second.
+func (j *JTTApp) onLevel(level recorder.Level) {
+	if j.app == nil {
+		return
+	}
+	j.app.Event.Emit("input-level", level)
+
+	seconds := int(level.Elapsed)
+	if int64(seconds) == j.elapsed.Swap(int64(seconds)) || j.machine.Current() != state.Recording {
+		return
+	}
+	if item := j.statusItem.Load(); item != nil {
+		item.SetLabel(recordingLabel(seconds))
 	}
+}
+
+// onNoInput warns that the mic seems muted before a whole dictation is lost.
+func (j *JTTApp) onNoInput() {
+	if j.app == nil {
+		return
+	}
+	j.app.Event.Emit("input-silent", "No audio from the microphone. Is it muted?")
+}
 
-	// Pause media if enabled and playing
-	j.mediaWasPlaying = false
-	if j.cfg.PauseMediaOnRecord && media.IsPlaying() {
+// StartRecording begins a recording, even while earlier ones are still being
+// processed. It returns a *state.TransitionError if already recording.
+func (j *JTTApp) StartRecording() error {
+	return j.startRecording("")
+}
+
+// startRecording begins a recording that will be transcribed with the named
+// profile's settings.
+func (j *JTTApp) startRecording(profile string) error {
+	if err := j.machine.Transition(state.Recording); err != nil {
+		return err
+	}
+	j.failure.Store(nil)
+
+	// Pause media if enabled and playing; it may already be paused by a
+	// recording that is still queued
+	cfg := j.config()
+	j.mu.Lock()
+	j.profile = profile
+	if cfg.StreamTranscription {
+		j.stream.Store(j.newStream(cfg, profile))
+	}
+	if !j.mediaWasPlaying && cfg.PauseMediaOnRecord && media.IsPlaying() {
 		media.Pause()
 		j.mediaWasPlaying = true
 		logger.Info("Paused media playback")
 	}
+	j.mu.Unlock()
 
-	if err := j.recorder.Start(); err != nil {
+	if err := j.source.Start(); err != nil {
 		logger.Error("Failed to start recording: %v", err)
-		// Resume media if we paused it
-		if j.mediaWasPlaying {
-			media.Play()
-		}
+		j.mu.Lock()
+		j.dropStream()
+		j.settle(state.Error)
+		j.mu.Unlock()
 		return err
 	}
 
 	logger.Info("Recording started")
-	j.updateState(StateRecording)
 	return nil
 }
 
+// StopRecording ends the current recording, waits for it to go through the
+// pipeline and returns the final text. It returns a *state.TransitionError if
+// the app is not recording.
 func (j *JTTApp) StopRecording() (string, error) {
-	if j.state != StateRecording {
-		return "", nil
+	job, err := j.stopRecording()
+	if err != nil {
+		return "", err
 	}
 
-	j.updateState(StateProcessing)
+	job, err = j.queue.Wait(job.ID)
+	if err != nil {
+		return "", err
+	}
+	switch job.Stage {
+	case jobs.Cancelled:
+		return "", errDictationCancelled
+	case jobs.Failed:
+		return "", errors.New(job.Error)
+	}
+	return job.Text, nil
+}
+
+// stopRecording ends the current recording and queues it for processing
+// without waiting, so the hotkey is free for the next dictation.
+func (j *JTTApp) stopRecording() (jobs.Job, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.machine.Transition(state.Processing); err != nil {
+		return jobs.Job{}, err
+	}
 
-	if err := j.recorder.Stop(); err != nil {
+	if err := j.source.Stop(); err != nil {
 		logger.Error("Failed to stop recording: %v", err)
-		j.updateState(StateIdle)
-		return "", err
+		os.Remove(j.source.AudioPath())
+		j.dropStream()
+		j.settle(state.Error)
+		return jobs.Job{}, err
 	}
 
-	logger.Info("Recording stopped, starting transcription")
-	trans := transcriber.New(j.cfg.WhisperModel, j.cfg.FilterHallucinations)
-	whisperResult, err := trans.Transcribe(j.recorder.AudioPath())
+	// Give the job its own copy of the audio so the next recording doesn't
+	// overwrite it
+	id := history.NewID()
+	path := filepath.Join(j.queueDir(), id+".wav")
+	err := os.MkdirAll(j.queueDir(), 0755)
+	if err == nil {
+		err = os.Rename(j.source.AudioPath(), path)
+	}
 	if err != nil {
-		logger.Error("Transcription failed: %v", err)
-		j.updateState(StateIdle)
-		return "", err
+		logger.Error("Failed to queue recording: %v", err)
+		j.dropStream()
+		j.settle(state.Error)
+		return jobs.Job{}, err
 	}
-	logger.Info("Transcription completed in %.2fs", whisperResult.Seconds)
+	if stream := j.stream.Swap(nil); stream != nil {
+		j.streams.Store(id, stream)
+	}
+
+	logger.Info("Stopping recording, queueing transcription")
+	return j.queue.Add(id, path, j.profile), nil
+}
 
-	// Skip LLM cleaning if there's no text
-	var cleanResult *cleaner.CleanResult
-	if whisperResult.Text == "" {
-		cleanResult = &cleaner.CleanResult{Text: "", Seconds: 0}
+// runJob takes a queued recording through transcription, cleaning and
+// delivery, and adds it to history.
+func (j *JTTApp) runJob(ctx context.Context, job jobs.Job, stage func(string)) (string, error) {
+	// Settings changed while the job runs apply from the next one
+	cfg := j.config()
+	p := j.pipelineFor(cfg, job.Profile)
+	p.OnStage = func(s pipeline.Stage) { stage(string(s)) }
+	recorded, _ := audio.Duration(job.AudioPath)
+
+	v, streamed := j.streams.LoadAndDelete(job.ID)
+	if streamed {
+		// The chunks were preprocessed as they came in; the saved audio
+		// is filtered after delivery, untrimmed so segment times match it
+		p.Transcriber = v.(*transcriber.Stream)
+		p.AudioFilters = []pipeline.AudioFilter{j.preprocessor(cfg, false)}
 	} else {
-		prompt := j.cfg.LLMPrompt
-		if prompt == "" {
-			prompt = config.DefaultLLMPrompt
+		p.FilterAudio(job.AudioPath)
+	}
+
+	result, err := p.Process(ctx, job.AudioPath)
+	if ctx.Err() != nil {
+		logger.Info("Dictation cancelled")
+		return "", ctx.Err()
+	}
+	if err != nil {
+		logger.Error("Dictation failed: %v", err)
+		j.reportFailure(err)
+		// Keep the recording in history so it can be re-run
+		entry := j.newEntry(cfg, job.ID)
+		entry.Profile = job.Profile
+		entry.RecordedDuration = recorded
+		entry.Error = err.Error()
+		j.saveEntry(entry, &pipeline.Result{}, job.AudioPath)
+		// Flash the error in the tray; the queue decides where to settle
+		j.mu.Lock()
+		if j.machine.Current() == state.Processing {
+			j.machine.Transition(state.Error)
+			j.machine.Transition(state.Processing)
 		}
-		clean := cleaner.New(j.cfg.OllamaModel, j.cfg.UseOllama, prompt)
-		cleanResult, err = clean.Clean(whisperResult.Text)
-		if err != nil {
-			logger.Error("LLM cleaning failed: %v", err)
-			cleanResult = &cleaner.CleanResult{Text: whisperResult.Text, Seconds: 0}
+		j.mu.Unlock()
+		return "", err
+	}
+
+	if streamed {
+		p.FilterAudio(job.AudioPath)
+	}
+
+	entry := j.newEntry(cfg, job.ID)
+	entry.Profile = job.Profile
+	entry.RecordedDuration = recorded
+	j.saveEntry(entry, result, job.AudioPath)
+	// It's still here if history couldn't take it; don't offer it again
+	// after a crash
+	os.Remove(job.AudioPath)
+	return result.Text, nil
+}
+
+// queueDir holds recordings waiting to be processed.
+func (j *JTTApp) queueDir() string {
+	return filepath.Join(filepath.Dir(j.source.AudioPath()), "jobs")
+}
+
+// chunksDir holds the chunks cut from recordings for streaming transcription.
+func (j *JTTApp) chunksDir() string {
+	return filepath.Join(filepath.Dir(j.source.AudioPath()), "chunks")
+}
+
+// reportFailure tells the user a dictation failed, in the tray and the
+// settings window, so a failure doesn't look like the app quietly going idle.
+func (j *JTTApp) reportFailure(err error) {
+	message := "Last dictation failed"
+	var timeout *transcriber.TimeoutError
+	if errors.As(err, &timeout) {
+		message = "Last dictation timed out"
+	}
+	j.failure.Store(&message)
+
+	if j.app == nil {
+		return
+	}
+	j.app.Event.Emit("dictation-error", err.Error())
+}
+
+// onJobsChange tells the frontend about queued jobs and their stage.
+func (j *JTTApp) onJobsChange(list []jobs.Job) {
+	// Jobs cancelled before they ran still hold their stream
+	for _, job := range list {
+		if job.Finished() {
+			if v, ok := j.streams.LoadAndDelete(job.ID); ok {
+				v.(*transcriber.Stream).Cancel()
+			}
 		}
 	}
 
-	// Add to history (keep last 5)
+	if j.app == nil {
+		return
+	}
+	j.app.Event.Emit("jobs-change", list)
+	if j.machine.Current() == state.Processing {
+		j.refreshMenu()
+	}
+}
+
+// onQueueIdle returns to Idle once the last queued recording is done, unless
+// a new recording has started meanwhile.
+func (j *JTTApp) onQueueIdle() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.queue.Pending() == 0 && j.machine.Current() == state.Processing {
+		j.settle("")
+	}
+}
+
+// settle leaves the current transient state for Processing if jobs are still
+// queued, or Idle otherwise, going through via first. Media is resumed once
+// nothing is left to do. It must be called with j.mu held.
+func (j *JTTApp) settle(via state.State) {
+	if j.queue.Pending() > 0 {
+		j.machine.ResetTo(via, state.Processing)
+		return
+	}
+	j.machine.ResetTo(via, state.Idle)
+	j.resumeMedia()
+}
+
+// newEntry returns a history entry for a dictation run with cfg.
+func (j *JTTApp) newEntry(cfg *config.Config, id string) config.TranscriptionEntry {
 	entry := config.TranscriptionEntry{
-		Timestamp:     time.Now().Unix(),
-		WhisperTime:   whisperResult.Seconds,
-		WhisperOutput: whisperResult.Text,
-		LLMTime:       cleanResult.Seconds,
-		LLMOutput:     cleanResult.Text,
-	}
-	j.history = append(j.history, entry)
-	if len(j.history) > 5 {
-		j.history = j.history[len(j.history)-5:]
-	}
-
-	// Copy to clipboard using pbcopy
-	clipCmd := exec.Command("pbcopy")
-	clipCmd.Stdin = strings.NewReader(cleanResult.Text)
-	if err := clipCmd.Run(); err != nil {
-		logger.Error("Failed to copy to clipboard: %v", err)
+		ID:           id,
+		WhisperModel: j.newBackend(cfg, "").Model(),
+	}
+	if cfg.UseOllama {
+		entry.OllamaModel = cfg.OllamaModel
+	}
+	return entry
+}
+
+// saveEntry fills in entry with a finished dictation's results and adds it to
+// history, moving its audio from audioPath into the history store.
+func (j *JTTApp) saveEntry(entry config.TranscriptionEntry, result *pipeline.Result, audioPath string) config.TranscriptionEntry {
+	entry.Timestamp = time.Now().Unix()
+	entry.WhisperTime = result.WhisperSeconds
+	entry.WhisperOutput = result.WhisperText
+	entry.Language = result.Language
+	entry.Segments = segments(result)
+	entry.Vocabulary = result.Vocabulary
+	entry.Filtered = filtered(result)
+	entry.LLMTime = result.ProcessSeconds
+	entry.LLMOutput = result.Text
+	if duration, err := audio.Duration(audioPath); err == nil {
+		entry.AudioDuration = duration
+	}
+	// Keep this recording's audio with the entry so it can be reprocessed
+	if path, err := j.history.KeepAudio(entry.ID, audioPath); err != nil {
+		logger.Error("Failed to keep audio: %v", err)
 	} else {
-		logger.Info("Copied to clipboard: %d chars", len(cleanResult.Text))
+		entry.AudioPath = path
+	}
+	entry, err := j.history.Add(entry)
+	if err != nil {
+		logger.Error("Failed to save history: %v", err)
 	}
+	return entry
+}
 
-	// Small delay to ensure clipboard is ready
-	time.Sleep(50 * time.Millisecond)
+// TranscribeRecovered transcribes the recordings interrupted by a crash,
+// copies the text to the clipboard and adds them to history. Nothing is
+// pasted, since whatever had focus back then is long gone.
+func (j *JTTApp) TranscribeRecovered() (string, error) {
+	rec := j.recovered.Swap(nil)
+	if rec == nil {
+		return "", errors.New("no interrupted recording")
+	}
+	j.refreshMenu()
+
+	cfg := j.config()
+	var texts []string
+	var failed recorder.Recovery
+	var lastErr error
+	for _, r := range rec.Recordings {
+		logger.Info("Transcribing interrupted recording %s", r.Path)
+		p := j.newPipeline(cfg, "")
+		p.Sinks = nil
+		result, err := p.Process(context.Background(), r.Path)
+		if err != nil {
+			logger.Error("Failed to transcribe interrupted recording: %v", err)
+			failed.Add(r)
+			lastErr = err
+			continue
+		}
+		j.saveEntry(j.newEntry(cfg, history.NewID()), result, r.Path)
+		if result.Text != "" {
+			texts = append(texts, result.Text)
+		}
+	}
+
+	text := strings.Join(texts, "\n\n")
+	if text != "" {
+		if err := (pipeline.Clipboard{}).Deliver(text); err != nil {
+			logger.Error("Failed to copy interrupted recordings: %v", err)
+		}
+	}
+	if lastErr != nil {
+		// Keep offering the ones that failed
+		j.recovered.CompareAndSwap(nil, &failed)
+		j.refreshMenu()
+		return text, lastErr
+	}
+	return text, nil
+}
+
+// DiscardRecovered deletes the recordings interrupted by a crash.
+func (j *JTTApp) DiscardRecovered() error {
+	rec := j.recovered.Swap(nil)
+	if rec == nil {
+		return nil
+	}
+	j.refreshMenu()
+	var firstErr error
+	for _, r := range rec.Recordings {
+		logger.Info("Discarding interrupted recording %s", r.Path)
+		if err := os.Remove(r.Path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CancelRecording discards the active recording without pasting anything.
+// When nothing is being recorded it aborts every queued dictation instead. It
+// returns a *state.TransitionError if there is nothing to cancel.
+func (j *JTTApp) CancelRecording() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	from := j.machine.Current()
+	if err := j.machine.Transition(state.Cancelled); err != nil {
+		return err
+	}
+
+	if from == state.Processing {
+		// Running jobs see their context cancelled, killing whisper-cli or
+		// the Ollama request
+		logger.Info("Cancelling queued transcriptions")
+		j.queue.CancelAll()
+		j.machine.Transition(state.Idle)
+		j.resumeMedia()
+		return nil
+	}
+
+	j.dropStream()
+	if err := j.source.Stop(); err != nil {
+		logger.Error("Failed to stop recording: %v", err)
+	}
+	os.Remove(j.source.AudioPath())
+	logger.Info("Recording cancelled")
+
+	j.settle("")
+	return nil
+}
+
+// TranscribeFileOptions tunes a file transcription. Empty fields fall back
+// to the current settings.
+type TranscribeFileOptions struct {
+	WhisperModel string `json:"whisperModel"` // model path, or file name in the model directory
+	Language     string `json:"language"`
+	NoClean      bool   `json:"noClean"` // skip LLM cleaning even if it is enabled
+	Copy         bool   `json:"copy"`    // also copy the text to the clipboard
+}
+
+// TranscribeProgress is emitted as "transcribe-progress" while a file is
+// transcribed. Percent is only set during the transcribing stage.
+type TranscribeProgress struct {
+	Path    string `json:"path"`
+	Stage   string `json:"stage"`
+	Percent int    `json:"percent,omitempty"`
+}
+
+// TranscribeFile converts an existing audio file, transcribes and cleans it,
+// and adds it to history. Nothing is pasted. Progress is reported to
+// onProgress, if set, and to the frontend.
+func (j *JTTApp) TranscribeFile(path string, opts TranscribeFileOptions, onProgress func(TranscribeProgress)) (config.TranscriptionEntry, error) {
+	progress := func(stage string, percent int) {
+		p := TranscribeProgress{Path: path, Stage: stage, Percent: percent}
+		if onProgress != nil {
+			onProgress(p)
+		}
+		if j.app != nil {
+			j.app.Event.Emit("transcribe-progress", p)
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return config.TranscriptionEntry{}, err
+	}
+
+	cfg := j.config()
+	entry := j.newEntry(cfg, history.NewID())
+	entry.Source = path
+
+	// Convert into the cache; saveEntry moves it into history
+	progress("converting", 0)
+	cacheDir := filepath.Dir(j.recorder.AudioPath())
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return config.TranscriptionEntry{}, err
+	}
+	wavPath := filepath.Join(cacheDir, "import-"+entry.ID+".wav")
+	defer os.Remove(wavPath)
+	if err := audio.Convert(context.Background(), path, wavPath); err != nil {
+		return config.TranscriptionEntry{}, err
+	}
+
+	t := j.newTranscriber(cfg, "", opts.WhisperModel, opts.Language)
+	entry.WhisperModel = t.Model()
+	t.OnProgress(func(percent int) { progress(string(pipeline.StageTranscribing), percent) })
+
+	// Imported files skip the preprocessing chain: they weren't recorded
+	// through our mic, and long files would take a lot of memory
+	p := j.newPipeline(cfg, "")
+	p.Transcriber = t
+	p.Sinks = nil
+	if opts.Copy {
+		p.Sinks = []pipeline.Sink{pipeline.Clipboard{}}
+	}
+	if opts.NoClean {
+		p.Processors = nil
+		entry.OllamaModel = ""
+	}
+	p.OnStage = func(s pipeline.Stage) { progress(string(s), 0) }
+
+	logger.Info("Transcribing file %s", path)
+	result, err := p.Process(context.Background(), wavPath)
+	if err != nil {
+		logger.Error("Failed to transcribe %s: %v", path, err)
+		return config.TranscriptionEntry{}, err
+	}
+
+	entry = j.saveEntry(entry, result, wavPath)
+	progress("done", 0)
+	return entry, nil
+}
+
+// ReprocessOptions selects what to re-run for a history entry. Empty fields
+// fall back to the current settings.
+type ReprocessOptions struct {
+	Transcribe   bool   `json:"transcribe"`
+	Clean        bool   `json:"clean"`
+	WhisperModel string `json:"whisperModel"` // model path, or file name in the model directory
+	Language     string `json:"language"`
+	LLMPrompt    string `json:"llmPrompt"`
+}
+
+// Reprocess re-runs transcription and/or cleaning for a history entry and
+// stores the result as a new revision on it. Nothing is pasted.
+func (j *JTTApp) Reprocess(entryID string, opts ReprocessOptions) (config.TranscriptionEntry, error) {
+	entry, ok := j.history.Get(entryID)
+	if !ok {
+		return config.TranscriptionEntry{}, fmt.Errorf("history entry not found: %s", entryID)
+	}
+	if !opts.Transcribe && !opts.Clean {
+		return config.TranscriptionEntry{}, errors.New("nothing to reprocess: enable transcription or cleaning")
+	}
+
+	cfg := j.config()
+	t := j.newTranscriber(cfg, entry.Profile, opts.WhisperModel, opts.Language)
+	prompt := opts.LLMPrompt
+	if prompt == "" {
+		prompt = cfg.LLMPrompt
+	}
+	if prompt == "" {
+		prompt = config.DefaultLLMPrompt
+	}
+
+	p := &pipeline.Pipeline{
+		Transcriber: t,
+	}
+	rev := config.Revision{
+		Timestamp: time.Now().Unix(),
+	}
+	if opts.Clean {
+		p.Processors = []pipeline.Processor{cleaner.New(cfg.OllamaModel, true, prompt)}
+		rev.LLMPrompt = prompt
+		rev.OllamaModel = cfg.OllamaModel
+	}
 
-	// Paste using Cmd+V
-	pasteCmd := exec.Command("osascript", "-e", `tell application "System Events" to keystroke "v" using command down`)
-	if output, err := pasteCmd.CombinedOutput(); err != nil {
-		logger.Error("Failed to paste: %v, output: %s", err, string(output))
+	var result *pipeline.Result
+	var err error
+	if opts.Transcribe {
+		if entry.AudioPath == "" {
+			return config.TranscriptionEntry{}, errors.New("entry has no saved audio")
+		}
+		logger.Info("Reprocessing %s with %s", entryID, t.Model())
+		rev.WhisperModel = t.Model()
+		result, err = p.Process(context.Background(), entry.AudioPath)
+		if err == nil {
+			rev.Language = result.Language
+			rev.Segments = segments(result)
+			rev.Vocabulary = result.Vocabulary
+			rev.Filtered = filtered(result)
+		}
 	} else {
-		logger.Info("Paste command executed")
+		// Clean the most recent transcription again
+		text, whisperModel := entry.WhisperOutput, entry.WhisperModel
+		if n := len(entry.Revisions); n > 0 {
+			text, whisperModel = entry.Revisions[n-1].WhisperOutput, entry.Revisions[n-1].WhisperModel
+		}
+		logger.Info("Re-cleaning %s", entryID)
+		rev.WhisperModel = whisperModel
+		result, err = p.ProcessText(context.Background(), text)
+	}
+	if err != nil {
+		logger.Error("Reprocessing failed: %v", err)
+		return config.TranscriptionEntry{}, err
 	}
 
-	// Resume media if it was playing before recording
+	rev.WhisperTime = result.WhisperSeconds
+	rev.WhisperOutput = result.WhisperText
+	rev.LLMTime = result.ProcessSeconds
+	rev.LLMOutput = result.Text
+	return j.history.AddRevision(entryID, rev)
+}
+
+// resumeMedia resumes media playback if it was playing before recording. It
+// must be called with j.mu held.
+func (j *JTTApp) resumeMedia() {
 	if j.mediaWasPlaying {
 		media.Play()
 		logger.Info("Resumed media playback")
 		j.mediaWasPlaying = false
 	}
+}
+
+// segments converts a pipeline result's segments for history.
+func segments(result *pipeline.Result) []config.Segment {
+	var out []config.Segment
+	for _, seg := range result.Segments {
+		out = append(out, config.Segment(seg))
+	}
+	return out
+}
 
-	j.updateState(StateIdle)
-	return cleanResult.Text, nil
+// filtered converts what a pipeline result's hallucination filter removed for
+// history.
+func filtered(result *pipeline.Result) []config.FilteredText {
+	var out []config.FilteredText
+	for _, f := range result.Filtered {
+		out = append(out, config.FilteredText(f))
+	}
+	return out
+}
+
+// newStream starts streaming transcription with cfg for a recording made
+// with the named profile. Chunks are preprocessed like whole recordings, except that
+// silence is left in so segment times line up with the recording.
+func (j *JTTApp) newStream(cfg *config.Config, profile string) *transcriber.Stream {
+	dir := filepath.Join(j.chunksDir(), history.NewID())
+	stream := j.newTranscriber(cfg, profile, "", "").NewStream(dir, j.preprocessor(cfg, false).Filter)
+	stream.OnPartial(func(text string) {
+		if j.app != nil {
+			j.app.Event.Emit("partial-transcript", text)
+		}
+	})
+	return stream
+}
+
+// onChunk hands a chunk cut from the current recording to its stream.
+func (j *JTTApp) onChunk(chunk recorder.Chunk) {
+	if stream := j.stream.Load(); stream != nil {
+		stream.Add(chunk.Index, chunk.Start, chunk.PCM)
+	}
+}
+
+// dropStream cancels the current recording's stream, if any.
+func (j *JTTApp) dropStream() {
+	if stream := j.stream.Swap(nil); stream != nil {
+		stream.Cancel()
+	}
+}
+
+// newPipeline assembles the dictation pipeline from cfg and the named
+// profile, if any: audio cleanup, the configured transcription backend,
+// optional Ollama cleaning, and clipboard + paste output.
+func (j *JTTApp) newPipeline(cfg *config.Config, profile string) *pipeline.Pipeline {
+	prompt := cfg.LLMPrompt
+	if prompt == "" {
+		prompt = config.DefaultLLMPrompt
+	}
+	return &pipeline.Pipeline{
+		AudioFilters: []pipeline.AudioFilter{j.preprocessor(cfg, true)},
+		Transcriber:  j.newTranscriber(cfg, profile, "", ""),
+		Processors: []pipeline.Processor{
+			cleaner.New(cfg.OllamaModel, cfg.UseOllama, prompt),
+		},
+		Sinks: []pipeline.Sink{pipeline.Clipboard{}, pipeline.Paste{}},
+	}
+}
+
+// newBackend returns the transcription backend cfg selects. model, if set,
+// replaces the whisper-cli model: a path, or a file name in the model
+// directory.
+func (j *JTTApp) newBackend(cfg *config.Config, model string) transcriber.Backend {
+	if model == "" {
+		model = cfg.WhisperModel
+	} else if !filepath.IsAbs(model) {
+		model = filepath.Join(whisperModelDir(), model)
+	}
+	backend, err := transcriber.NewBackend(transcriber.Settings{
+		Backend:   cfg.TranscriptionBackend,
+		ModelPath: model,
+		URL:       cfg.TranscriptionURL,
+		Model:     cfg.TranscriptionModel,
+		APIKey:    cfg.TranscriptionAPIKey,
+	})
+	if err != nil {
+		logger.Error("%v, using whisper-cli", err)
+		backend = transcriber.NewWhisperCLI(model)
+	}
+	return backend
+}
+
+// newTranscriber wraps newBackend(cfg, model) for the pipeline, using the named
+// profile's language and vocabulary. An empty language means the profile's,
+// or else the configured one.
+func (j *JTTApp) newTranscriber(cfg *config.Config, profile, model, language string) *transcriber.Transcriber {
+	p, _ := cfg.Profile(profile)
+	if language == "" {
+		language = p.Language
+	}
+	if language == "" {
+		language = cfg.Language
+	}
+	t := transcriber.New(j.newBackend(cfg, model), language, j.hallucinationFilter(cfg))
+	// Profile terms come first so they survive if the prompt is too long
+	t.SetVocabulary(append(append([]string(nil), p.Vocabulary...), cfg.Vocabulary...))
+	return t
+}
+
+// hallucinationFilter builds the hallucination filter from cfg, or returns
+// nil if filtering is off. Patterns that don't compile are
+// skipped.
+func (j *JTTApp) hallucinationFilter(cfg *config.Config) *transcriber.Filter {
+	if !cfg.FilterHallucinations {
+		return nil
+	}
+	var rules []transcriber.Rule
+	for _, p := range cfg.HallucinationPatterns {
+		rule, err := hallucinationRule(p)
+		if err != nil {
+			logger.Error("Skipping hallucination pattern: %v", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	f := transcriber.NewFilter(rules...)
+	if cfg.NoSpeechThreshold > 0 {
+		f.NoSpeechThreshold = cfg.NoSpeechThreshold
+	}
+	return f
+}
+
+// hallucinationRule converts a configured pattern to a filter rule, named
+// after the pattern so history shows which one removed the text.
+func hallucinationRule(p config.HallucinationPattern) (transcriber.Rule, error) {
+	if !p.Regex {
+		return transcriber.Rule{Name: p.Pattern, Exact: p.Pattern}, nil
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return transcriber.Rule{}, fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+	}
+	return transcriber.Rule{Name: p.Pattern, Regex: re}, nil
+}
+
+// preprocessor builds the audio cleanup chain from cfg. The
+// order matters: rumble and noise are removed before silence is detected, and
+// loudness is measured on what's left. Without trim, silence is kept even if
+// trimming is on, for audio whose timing must not shift.
+func (j *JTTApp) preprocessor(cfg *config.Config, trim bool) preprocess.Chain {
+	var steps []preprocess.Step
+	if cfg.HighPassFilter {
+		steps = append(steps, preprocess.HighPass{CutoffHz: 80})
+	}
+	if cfg.NoiseReduction {
+		steps = append(steps, preprocess.Denoise{Strength: 2, Floor: 0.1})
+	}
+	if trim && cfg.TrimSilence {
+		steps = append(steps, preprocess.TrimSilence{
+			ThresholdDB: cfg.SilenceThreshold(),
+			Padding:     250 * time.Millisecond,
+		})
+	}
+	if cfg.NormalizeLoudness {
+		steps = append(steps, preprocess.Normalize{
+			TargetDB:  -20,
+			GateDB:    cfg.SilenceThreshold(),
+			MaxGainDB: 30,
+		})
+	}
+	return preprocess.Chain{Steps: steps}
 }
 
 // JTTService exposes methods to the frontend
@@ -501,17 +1370,48 @@ func NewJTTService(jtt *JTTApp) *JTTService {
 }
 
 func (s *JTTService) GetState() string {
-	return string(s.jtt.state)
+	return string(s.jtt.machine.Current())
 }
 
 func (s *JTTService) GetConfig() *config.Config {
-	return s.jtt.cfg
+	return s.jtt.config()
 }
 
 func (s *JTTService) SaveConfig(cfg *config.Config) error {
-	s.jtt.cfg = cfg
+	if _, err := transcriber.NewBackend(transcriber.Settings{Backend: cfg.TranscriptionBackend}); err != nil {
+		return err
+	}
+	if cfg.Language != "" && !transcriber.IsLanguage(cfg.Language) {
+		return fmt.Errorf("unknown language: %s", cfg.Language)
+	}
+	for _, p := range cfg.HallucinationPatterns {
+		if _, err := hallucinationRule(p); err != nil {
+			return err
+		}
+	}
+	if cfg.NoSpeechThreshold < 0 || cfg.NoSpeechThreshold > 1 {
+		return fmt.Errorf("no-speech threshold must be between 0 and 1")
+	}
+	for _, p := range cfg.Profiles {
+		if p.Language != "" && !transcriber.IsLanguage(p.Language) {
+			return fmt.Errorf("profile %s: unknown language: %s", p.Name, p.Language)
+		}
+	}
+	s.jtt.cfgMu.Lock()
+	defer s.jtt.cfgMu.Unlock()
+	s.jtt.cfg.Store(cfg)
 	// Update recorder's microphone setting
 	s.jtt.recorder.SetMicrophone(cfg.Microphone)
+	s.jtt.recorder.SetBackend(cfg.AudioBackend)
+	s.jtt.recorder.SetAutoStop(cfg.SilenceThreshold(), cfg.SilenceStop())
+	s.jtt.recorder.SetChunking(cfg.StreamTranscription, cfg.SilenceThreshold())
+	if err := s.jtt.recorder.SetPreRoll(time.Duration(cfg.PreRollMs) * time.Millisecond); err != nil {
+		logger.Error("Failed to open mic for pre-roll: %v", err)
+	}
+	s.jtt.refreshMenu()
+	if err := s.jtt.history.SetRetention(cfg.HistoryMaxEntries, cfg.HistoryMaxAgeDays); err != nil {
+		logger.Error("Failed to apply history retention: %v", err)
+	}
 	return cfg.Save()
 }
 
@@ -527,6 +1427,42 @@ func (s *JTTService) StopRecording() (string, error) {
 	return s.jtt.StopRecording()
 }
 
+func (s *JTTService) CancelRecording() error {
+	return s.jtt.CancelRecording()
+}
+
+// TranscribeFile transcribes an existing audio file into history without
+// pasting it
+func (s *JTTService) TranscribeFile(path string, opts TranscribeFileOptions) (config.TranscriptionEntry, error) {
+	return s.jtt.TranscribeFile(path, opts, nil)
+}
+
+// GetJobs returns queued, running and recently finished dictations, oldest
+// first
+func (s *JTTService) GetJobs() []jobs.Job {
+	return s.jtt.queue.List()
+}
+
+func (s *JTTService) CancelJob(id string) error {
+	if !s.jtt.queue.Cancel(id) {
+		return fmt.Errorf("no queued job %s", id)
+	}
+	return nil
+}
+
+// GetRecoveredRecording returns the recordings interrupted by a crash, or nil
+func (s *JTTService) GetRecoveredRecording() *recorder.Recovery {
+	return s.jtt.recovered.Load()
+}
+
+func (s *JTTService) TranscribeRecovered() (string, error) {
+	return s.jtt.TranscribeRecovered()
+}
+
+func (s *JTTService) DiscardRecovered() error {
+	return s.jtt.DiscardRecovered()
+}
+
 func (s *JTTService) GetOllamaModels() []string {
 	models, err := cleaner.ListModels()
 	if err != nil {
@@ -570,12 +1506,25 @@ func (s *JTTService) CheckDependencies() DependencyStatus {
 	status.Ollama = cleaner.IsOllamaRunning()
 	status.NowPlaying = media.IsAvailable()
 
-	_, err := os.Stat(s.jtt.cfg.WhisperModel)
+	cfg := s.jtt.config()
+	_, err := os.Stat(cfg.WhisperModel)
 	status.HasModel = err == nil
 
+	// Server backends bring their own whisper and model
+	if b := cfg.TranscriptionBackend; b != "" && b != transcriber.BackendWhisperCLI {
+		status.Whisper = true
+		status.HasModel = true
+	}
+
 	return status
 }
 
+// whisperModelDir is where DownloadWhisperModel stores models
+func whisperModelDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "jtt")
+}
+
 func findBrewBinary() string {
 	paths := []string{
 		"/opt/homebrew/bin/brew", // Apple Silicon
@@ -625,6 +1574,12 @@ func (s *JTTService) GetAvailableWhisperModels() []WhisperModel {
 		{Name: "small.en", Size: "466MB", Speed: "Medium", Quality: "Better", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small.en.bin"},
 		{Name: "medium.en", Size: "1.5GB", Speed: "Slow", Quality: "Great", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-medium.en.bin"},
 		{Name: "large", Size: "3GB", Speed: "Slowest", Quality: "Best", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-large.bin"},
+		// Multilingual models, needed for languages other than English and
+		// for auto-detection
+		{Name: "base", Size: "142MB", Speed: "Fast", Quality: "Good", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.bin"},
+		{Name: "small", Size: "466MB", Speed: "Medium", Quality: "Better", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-small.bin"},
+		{Name: "medium", Size: "1.5GB", Speed: "Slow", Quality: "Great", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-medium.bin"},
+		{Name: "large-v3-turbo", Size: "1.6GB", Speed: "Medium", Quality: "Best", URL: "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-large-v3-turbo.bin"},
 	}
 }
 
@@ -640,8 +1595,9 @@ func (s *JTTService) DownloadWhisperModel(name, url string) error {
 		return err
 	}
 
-	s.jtt.cfg.WhisperModel = modelPath
-	return s.jtt.cfg.Save()
+	return s.jtt.updateConfig(func(cfg *config.Config) {
+		cfg.WhisperModel = modelPath
+	})
 }
 
 func (s *JTTService) GetDownloadedModels() []string {
@@ -662,8 +1618,45 @@ func (s *JTTService) GetDownloadedModels() []string {
 	return models
 }
 
-func (s *JTTService) GetHistory() []config.TranscriptionEntry {
-	return s.jtt.history
+// GetHistory returns a page of history entries, newest first. A limit of zero
+// returns everything after offset.
+func (s *JTTService) GetHistory(offset, limit int) history.Page {
+	return s.jtt.history.List(offset, limit)
+}
+
+// SearchHistory runs a ranked full-text search over whisper and LLM output.
+func (s *JTTService) SearchHistory(query history.Query) []history.SearchResult {
+	return s.jtt.history.Search(query)
+}
+
+// Reprocess re-runs an entry's saved audio or text with different settings
+// and returns the entry with the new revision appended.
+func (s *JTTService) Reprocess(entryID string, options ReprocessOptions) (config.TranscriptionEntry, error) {
+	return s.jtt.Reprocess(entryID, options)
+}
+
+// ExportHistory writes the entries with the given IDs, or all entries if ids
+// is empty, to path. format is markdown, json, csv, srt or vtt; if empty it
+// is inferred from the file extension.
+func (s *JTTService) ExportHistory(ids []string, format, path string) error {
+	f, err := parseExportFormat(format, path)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteFile(path, f, s.jtt.history.Select(ids)); err != nil {
+		logger.Error("Export failed: %v", err)
+		return err
+	}
+	logger.Info("Exported history to %s", path)
+	return nil
+}
+
+func (s *JTTService) DeleteHistoryEntry(id string) error {
+	return s.jtt.history.Delete(id)
+}
+
+func (s *JTTService) ClearHistory() error {
+	return s.jtt.history.Clear()
 }
 
 func (s *JTTService) GetDefaultPrompt() string {
@@ -678,8 +1671,26 @@ func (s *JTTService) GetRecentLogs() string {
 	return logger.GetRecentLogs(100)
 }
 
+// FitVocabulary returns how many of terms fit in whisper's prompt, in order,
+// so the settings window can show what gets left out.
+func (s *JTTService) FitVocabulary(terms []string) int {
+	_, used := transcriber.VocabularyPrompt(terms)
+	return len(used)
+}
+
+// GetAudioBackends returns "auto" plus the capture backends usable on this
+// machine.
+func (s *JTTService) GetAudioBackends() []string {
+	return recorder.AvailableBackends()
+}
+
+// GetActiveMicrophone returns the device used by the current or last recording
+func (s *JTTService) GetActiveMicrophone() recorder.Microphone {
+	return s.jtt.recorder.ActiveDevice()
+}
+
 func (s *JTTService) GetMicrophones() []recorder.Microphone {
-	mics, err := recorder.ListMicrophones()
+	mics, err := s.jtt.recorder.ListMicrophones()
 	if err != nil {
 		return []recorder.Microphone{{ID: "", Name: "System Default"}}
 	}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"jtt/internal/audio"
+	"jtt/internal/config"
+	"jtt/internal/history"
+	"jtt/internal/jobs"
+	"jtt/internal/pipeline"
+	"jtt/internal/recorder"
+	"jtt/internal/state"
+	"jtt/internal/transcriber"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource writes a second of silence for every recording.
+type fakeSource struct {
+	path string
+	w    *audio.Writer
+}
+
+func (s *fakeSource) Start() error {
+	w, err := audio.Create(s.path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(make([]byte, 32000)); err != nil {
+		w.Close()
+		return err
+	}
+	s.w = w
+	return nil
+}
+
+func (s *fakeSource) Stop() error {
+	if s.w == nil {
+		return errors.New("not recording")
+	}
+	err := s.w.Close()
+	s.w = nil
+	return err
+}
+
+func (s *fakeSource) AudioPath() string { return s.path }
+
+// transcribeFunc stands in for whisper.
+type transcribeFunc func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error)
+
+func (f transcribeFunc) Transcribe(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+	return f(ctx, audioPath)
+}
+
+type fakeSink struct {
+	mu        sync.Mutex
+	delivered []string
+}
+
+func (s *fakeSink) Deliver(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered = append(s.delivered, text)
+	return nil
+}
+
+func (s *fakeSink) texts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.delivered...)
+}
+
+// newTestApp returns an app that records from a fake source and runs
+// recordings through transcribe and into the returned sink, keeping history
+// in a temporary directory.
+func newTestApp(t *testing.T, transcribe transcribeFunc) (*JTTApp, *fakeSink) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := history.Open(filepath.Join(dir, "history.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.UseOllama = false
+	cfg.PauseMediaOnRecord = false
+	cfg.StreamTranscription = false
+
+	sink := &fakeSink{}
+	j := newJTTAppWith(cfg, store, recorder.New("", ""))
+	j.source = &fakeSource{path: filepath.Join(dir, "recording.wav")}
+	j.pipelineFor = func(cfg *config.Config, profile string) *pipeline.Pipeline {
+		return &pipeline.Pipeline{Transcriber: transcribe, Sinks: []pipeline.Sink{sink}}
+	}
+	return j, sink
+}
+
+func transcribeText(text string) transcribeFunc {
+	return func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+		return &transcriber.TranscribeResult{Text: text}, nil
+	}
+}
+
+// waitForState waits for the queue to settle the app in want.
+func waitForState(t *testing.T, j *JTTApp, want state.State) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for j.machine.Current() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("state is %s, want %s", j.machine.Current(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	j, sink := newTestApp(t, transcribeText("hello world"))
+
+	if err := j.StartRecording(); err != nil {
+		t.Fatal(err)
+	}
+	if got := j.machine.Current(); got != state.Recording {
+		t.Fatalf("state is %s after starting, want recording", got)
+	}
+	var terr *state.TransitionError
+	if err := j.StartRecording(); !errors.As(err, &terr) {
+		t.Errorf("starting twice: got %v, want a TransitionError", err)
+	}
+
+	text, err := j.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello world" {
+		t.Errorf("got %q, want %q", text, "hello world")
+	}
+	if got := sink.texts(); len(got) != 1 || got[0] != "hello world" {
+		t.Errorf("delivered %q", got)
+	}
+	waitForState(t, j, state.Idle)
+
+	page := j.history.List(0, 10)
+	if page.Total != 1 || page.Entries[0].LLMOutput != "hello world" {
+		t.Fatalf("history has %+v", page)
+	}
+	if page.Entries[0].AudioDuration != 1 {
+		t.Errorf("entry has %.2fs of audio, want 1s", page.Entries[0].AudioDuration)
+	}
+}
+
+func TestRecordWhileProcessing(t *testing.T) {
+	release := make(chan struct{})
+	j, sink := newTestApp(t, func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+		<-release
+		return &transcriber.TranscribeResult{Text: "dictation"}, nil
+	})
+
+	j.StartRecording()
+	first, err := j.stopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := j.machine.Current(); got != state.Processing {
+		t.Fatalf("state is %s with a job queued, want processing", got)
+	}
+
+	// The next dictation can start while the first is transcribed
+	if err := j.StartRecording(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := j.stopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	for _, job := range []jobs.Job{first, second} {
+		done, err := j.queue.Wait(job.ID)
+		if err != nil || done.Stage != jobs.Done {
+			t.Errorf("job %s ended %s: %v", job.ID, done.Stage, err)
+		}
+	}
+	if got := sink.texts(); len(got) != 2 {
+		t.Errorf("delivered %q, want two dictations", got)
+	}
+	waitForState(t, j, state.Idle)
+}
+
+func TestCancelRecording(t *testing.T) {
+	j, sink := newTestApp(t, transcribeText("unused"))
+
+	j.StartRecording()
+	if err := j.CancelRecording(); err != nil {
+		t.Fatal(err)
+	}
+	if got := j.machine.Current(); got != state.Idle {
+		t.Errorf("state is %s after cancelling, want idle", got)
+	}
+	if _, err := os.Stat(j.source.AudioPath()); !os.IsNotExist(err) {
+		t.Errorf("cancelled recording left behind: %v", err)
+	}
+	if len(j.queue.List()) != 0 || len(sink.texts()) != 0 {
+		t.Error("cancelled recording was processed")
+	}
+}
+
+func TestCancelProcessing(t *testing.T) {
+	started := make(chan struct{})
+	j, sink := newTestApp(t, func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	j.StartRecording()
+	job, err := j.stopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := j.CancelRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err = j.queue.Wait(job.ID)
+	if err != nil || job.Stage != jobs.Cancelled {
+		t.Errorf("job ended %s: %v", job.Stage, err)
+	}
+	if _, err := os.Stat(job.AudioPath); !os.IsNotExist(err) {
+		t.Errorf("cancelled job's audio left behind: %v", err)
+	}
+	if len(sink.texts()) != 0 {
+		t.Error("cancelled job delivered text")
+	}
+	waitForState(t, j, state.Idle)
+}
+
+func TestFailedTranscription(t *testing.T) {
+	j, _ := newTestApp(t, func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+		return nil, &transcriber.TimeoutError{Backend: "fake", Timeout: time.Second}
+	})
+
+	j.StartRecording()
+	if _, err := j.StopRecording(); err == nil {
+		t.Fatal("a failed transcription reported success")
+	}
+	waitForState(t, j, state.Idle)
+	if f := j.failure.Load(); f == nil || *f != "Last dictation timed out" {
+		t.Errorf("failure not reported: %v", f)
+	}
+
+	// The recording stays in history so it can be re-run
+	page := j.history.List(0, 10)
+	if page.Total != 1 || page.Entries[0].Error == "" {
+		t.Fatalf("history has %+v", page)
+	}
+	if _, err := os.Stat(page.Entries[0].AudioPath); err != nil {
+		t.Errorf("failed dictation's audio not kept: %v", err)
+	}
+}
+
+// TestHallucinationRules checks that text removed by the user's own patterns
+// is reported under the pattern that removed it.
+func TestHallucinationRules(t *testing.T) {
+	var rules []transcriber.Rule
+	for _, p := range []config.HallucinationPattern{
+		{Pattern: "See you next time"},
+		{Pattern: `(?i)\s*\bum+\b,?`, Regex: true},
+	} {
+		rule, err := hallucinationRule(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+	_, removed := transcriber.NewFilter(rules...).Apply([]transcriber.Segment{
+		{Text: " See you next time!"},
+		{Text: " So, um, the build is green."},
+	}, "")
+	if len(removed) != 2 || removed[0].Reason != "See you next time" || removed[1].Reason != `(?i)\s*\bum+\b,?` {
+		t.Errorf("removed %+v", removed)
+	}
+
+	if _, err := hallucinationRule(config.HallucinationPattern{Pattern: "(", Regex: true}); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+}
+
+// TestConcurrentDictation drives the app from several goroutines, as the
+// hotkeys, tray menu and control socket do, while settings change. Run it
+// with -race.
+func TestConcurrentDictation(t *testing.T) {
+	j, _ := newTestApp(t, func(ctx context.Context, audioPath string) (*transcriber.TranscribeResult, error) {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return &transcriber.TranscribeResult{Text: "text"}, nil
+	})
+	// The fake source isn't safe for concurrent use; the recorder is
+	var mu sync.Mutex
+	src := j.source
+	j.source = lockedSource{mu: &mu, src: src}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				switch (i + n) % 4 {
+				case 0:
+					j.StartRecording()
+				case 1:
+					j.stopRecording()
+				case 2:
+					j.CancelRecording()
+				default:
+					cfg := *j.config()
+					cfg.Language = []string{"en", "de"}[n%2]
+					j.cfg.Store(&cfg)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if j.machine.Current() == state.Recording {
+		j.CancelRecording()
+	}
+	j.queue.CancelAll()
+	waitForState(t, j, state.Idle)
+}
+
+type lockedSource struct {
+	mu  *sync.Mutex
+	src pipeline.AudioSource
+}
+
+func (s lockedSource) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Start()
+}
+
+func (s lockedSource) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Stop()
+}
+
+func (s lockedSource) AudioPath() string { return s.src.AudioPath() }
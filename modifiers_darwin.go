@@ -0,0 +1,9 @@
+package main
+
+import "golang.design/x/hotkey"
+
+// Platform modifiers for the "cmd" and "alt" names in the config.
+const (
+	modCmd = hotkey.ModCmd
+	modAlt = hotkey.ModOption
+)
@@ -0,0 +1,10 @@
+package main
+
+import "golang.design/x/hotkey"
+
+// Platform modifiers for the "cmd" and "alt" names in the config: X11 maps
+// the Super key to Mod4 and Alt to Mod1.
+const (
+	modCmd = hotkey.Mod4
+	modAlt = hotkey.Mod1
+)